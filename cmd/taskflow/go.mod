@@ -0,0 +1,10 @@
+module github.com/josuedeavila/taskflow/cmd/taskflow
+
+go 1.24.0
+
+require (
+	github.com/josuedeavila/taskflow v0.1.0
+	github.com/josuedeavila/taskflow/taskflowdef v0.1.0
+)
+
+require gopkg.in/yaml.v3 v3.0.1 // indirect
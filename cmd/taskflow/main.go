@@ -0,0 +1,260 @@
+// Command taskflow validates, displays and runs workflow definitions, see the
+// taskflowdef package for their format. Tasks use the built-in kinds:
+//
+//	shell  runs params.command with sh -c, see shellParams
+//
+// Usage:
+//
+//	taskflow validate FILE
+//	taskflow graph [-format text|dot|mermaid] FILE
+//	taskflow run [-v] [-graph OUT] FILE
+//
+// The exit code is 0 on success, 1 if the definition is invalid or the run
+// failed, and 2 for usage errors.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/josuedeavila/taskflow"
+	"github.com/josuedeavila/taskflow/taskflowdef"
+)
+
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+const usage = `Usage:
+  taskflow validate FILE    check a workflow definition
+  taskflow graph [-format text|dot|mermaid] FILE
+                            print the tasks of a workflow and their dependencies,
+                            as text, Graphviz DOT or a Mermaid flowchart
+  taskflow run [-v] [-graph OUT] FILE
+                            run a workflow, -v prints the output of each task and
+                            -graph writes the graph colored by outcome to OUT, in
+                            Mermaid if it ends in .mmd and in DOT otherwise
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command with args and returns its exit code.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	commands := map[string]func(ctx context.Context, args []string, stdout, stderr io.Writer) int{
+		"validate": validate,
+		"graph":    graph,
+		"run":      runWorkflow,
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "taskflow: unknown command %q\n%s", args[0], usage)
+		return exitUsage
+	}
+	return cmd(ctx, args[1:], stdout, stderr)
+}
+
+// parseFlags parses the flags of a command taking a single file argument.
+func parseFlags(fs *flag.FlagSet, args []string, stderr io.Writer) (string, bool) {
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, usage) }
+	if err := fs.Parse(args); err != nil {
+		return "", false
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintf(stderr, "taskflow %s: expected one workflow file\n%s", fs.Name(), usage)
+		return "", false
+	}
+	return fs.Arg(0), true
+}
+
+// load parses and builds the workflow in file with the built-in task kinds,
+// printing the errors found.
+func load(file string, stderr io.Writer) (*taskflowdef.Definition, *taskflow.Runner, bool) {
+	d, err := taskflowdef.ParseFile(file)
+	if err == nil {
+		var runner *taskflow.Runner
+		if runner, err = d.Build(builtins(), taskflowdef.WithLogger(taskflow.NoOpLogger{})); err == nil {
+			return d, runner, true
+		}
+	}
+
+	fmt.Fprintln(stderr, err)
+	return nil, nil, false
+}
+
+// validate checks a definition: its structure, the kinds of its tasks, cycles,
+// missing tasks and type mismatches.
+func validate(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	file, ok := parseFlags(flag.NewFlagSet("validate", flag.ContinueOnError), args, stderr)
+	if !ok {
+		return exitUsage
+	}
+	d, _, ok := load(file, stderr)
+	if !ok {
+		return exitFailure
+	}
+	fmt.Fprintf(stdout, "%s: %d tasks, ok\n", file, len(d.Tasks))
+	return exitOK
+}
+
+// graph prints the tasks of a definition in dependency order, each with the
+// tasks it depends on, or exports them as a DOT or Mermaid graph.
+func graph(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("graph", flag.ContinueOnError)
+	format := fs.String("format", "text", "output format: text, dot or mermaid")
+	file, ok := parseFlags(fs, args, stderr)
+	if !ok {
+		return exitUsage
+	}
+
+	switch *format {
+	case "text":
+	case "dot", "mermaid":
+		_, runner, ok := load(file, stderr)
+		if !ok {
+			return exitFailure
+		}
+		if err := export(stdout, runner, *format, nil); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitFailure
+		}
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "taskflow graph: unknown format %q\n%s", *format, usage)
+		return exitUsage
+	}
+
+	d, err := taskflowdef.ParseFile(file)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitFailure
+	}
+
+	tasks := ordered(d)
+	width := 0
+	for _, t := range tasks {
+		width = max(width, len(t.Name))
+	}
+	for _, t := range tasks {
+		kind := t.Func
+		if t.FanOut != nil {
+			kind = fmt.Sprintf("fan_out(%s, %s)", t.FanOut.Map, t.FanOut.FanIn)
+		}
+		line := fmt.Sprintf("%-*s  %s", width, t.Name, kind)
+		if len(t.Depends) > 0 {
+			line += "  <- " + strings.Join(t.Depends, ", ")
+		}
+		fmt.Fprintln(stdout, line)
+	}
+	return exitOK
+}
+
+// export writes the graph of runner in format, dot or mermaid, with the
+// outcome of the tasks in report if set.
+func export(w io.Writer, runner *taskflow.Runner, format string, report *taskflow.RunReport) error {
+	g, err := runner.Graph()
+	if err != nil {
+		return err
+	}
+	if format == "mermaid" {
+		return g.WriteMermaid(w, report)
+	}
+	return g.WriteDOT(w, report)
+}
+
+// ordered returns the tasks of a valid definition with dependencies first.
+func ordered(d *taskflowdef.Definition) []*taskflowdef.TaskDefinition {
+	byName := make(map[string]*taskflowdef.TaskDefinition)
+	for _, t := range d.Tasks {
+		byName[t.Name] = t
+	}
+
+	var tasks []*taskflowdef.TaskDefinition
+	visited := make(map[string]bool)
+	var visit func(t *taskflowdef.TaskDefinition)
+	visit = func(t *taskflowdef.TaskDefinition) {
+		if visited[t.Name] {
+			return
+		}
+		visited[t.Name] = true
+		for _, dep := range t.Depends {
+			visit(byName[dep])
+		}
+		tasks = append(tasks, t)
+	}
+	for _, t := range d.Tasks {
+		visit(t)
+	}
+	return tasks
+}
+
+// runWorkflow runs a definition, printing the progress of its tasks.
+func runWorkflow(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	verbose := fs.Bool("v", false, "print the output of each task")
+	graphFile := fs.String("graph", "", "write the graph colored by outcome to this file")
+	file, ok := parseFlags(fs, args, stderr)
+	if !ok {
+		return exitUsage
+	}
+	d, runner, ok := load(file, stderr)
+	if !ok {
+		return exitFailure
+	}
+
+	p := &progress{w: stdout, verbose: *verbose}
+	for _, t := range d.Tasks {
+		p.width = max(p.width, len(t.Name))
+	}
+	report, err := runner.WithObserver(p).Run(ctx)
+	if *graphFile != "" && report != nil {
+		if err := writeGraph(*graphFile, runner, report); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitFailure
+		}
+	}
+	if err != nil {
+		if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+			fmt.Fprintln(stderr, "taskflow: interrupted")
+		}
+		return exitFailure
+	}
+	return exitOK
+}
+
+// writeGraph writes the graph of a run to path, as a Mermaid flowchart if it
+// has the .mmd extension and in DOT otherwise.
+func writeGraph(path string, runner *taskflow.Runner, report *taskflow.RunReport) error {
+	format := "dot"
+	if filepath.Ext(path) == ".mmd" {
+		format = "mermaid"
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := export(f, runner, format, report); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// writeWorkflow writes a workflow definition to a temporary file.
+func writeWorkflow(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "workflow.yaml")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return path
+}
+
+const pipeline = `name: demo
+tasks:
+  - name: greet
+    func: shell
+    params:
+      command: echo hello
+  - name: shout
+    func: shell
+    depends: [greet]
+    params:
+      command: tr a-z A-Z
+`
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		code     int
+		expected string
+	}{
+		{"valid", pipeline, exitOK, "workflow.yaml: 2 tasks, ok"},
+		{"missing task", strings.Replace(pipeline, "[greet]", "[great]", 1), exitFailure, `workflow.yaml:9: task "shout" depends on undefined task "great"`},
+		{"unknown kind", strings.Replace(pipeline, "func: shell", "func: python", 1), exitFailure, `workflow.yaml:4: unknown function "python"`},
+		{"missing command", strings.Replace(pipeline, "command: echo hello", "cmd: echo hello", 1), exitFailure, `workflow.yaml:6: unknown param "cmd"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(context.Background(), []string{"validate", writeWorkflow(t, tt.data)}, &stdout, &stderr)
+			if code != tt.code {
+				t.Errorf("Expected exit code %d, got %d", tt.code, code)
+			}
+			if output := stdout.String() + stderr.String(); !strings.Contains(output, tt.expected) {
+				t.Errorf("Expected output containing %q, got %q", tt.expected, output)
+			}
+		})
+	}
+}
+
+func TestGraph(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run(context.Background(), []string{"graph", writeWorkflow(t, pipeline)}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	expected := "greet  shell\nshout  shell  <- greet\n"
+	if stdout.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, stdout.String())
+	}
+}
+
+func TestGraphFormats(t *testing.T) {
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"dot", "digraph taskflow {\n  rankdir=LR;\n  node [shape=box, style=\"rounded,filled\", fillcolor=\"#ffffff\"];\n  n0 [label=\"greet\"];\n  n1 [label=\"shout\"];\n  n0 -> n1;\n}\n"},
+		{"mermaid", "flowchart LR\n    n0[\"greet\"]\n    n1[\"shout\"]\n    n0 --> n1\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(context.Background(), []string{"graph", "-format", tt.format, writeWorkflow(t, pipeline)}, &stdout, &stderr)
+			if code != exitOK {
+				t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+			}
+			if stdout.String() != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, stdout.String())
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run(context.Background(), []string{"run", "-v", writeWorkflow(t, pipeline)}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stdout.String()+stderr.String())
+	}
+
+	for _, expected := range []string{
+		`(?m)^greet  started$`,
+		`(?m)^shout  succeeded in \S+$`,
+		`(?m)^       HELLO$`,
+		`(?m)^run \w+ succeeded in \S+: 2 succeeded$`,
+	} {
+		if !regexp.MustCompile(expected).MatchString(stdout.String()) {
+			t.Errorf("Expected output matching %q, got:\n%s", expected, stdout.String())
+		}
+	}
+}
+
+func TestRunFailure(t *testing.T) {
+	data := pipeline + `  - name: fail
+    func: shell
+    depends: [shout]
+    params:
+      command: echo boom >&2; exit 3
+  - name: after
+    func: shell
+    depends: [fail]
+    params:
+      command: "true"
+`
+	var stdout, stderr bytes.Buffer
+	if code := run(context.Background(), []string{"run", writeWorkflow(t, data)}, &stdout, &stderr); code != exitFailure {
+		t.Fatalf("Expected exit code 1, got %d", code)
+	}
+
+	for _, expected := range []string{
+		`(?m)^fail   failed in \S+: exit status 3: boom$`,
+		`(?m)^after  skipped$`,
+		`(?m)^run \w+ failed in \S+: 2 succeeded, 1 failed, 1 skipped$`,
+	} {
+		if !regexp.MustCompile(expected).MatchString(stdout.String()) {
+			t.Errorf("Expected output matching %q, got:\n%s", expected, stdout.String())
+		}
+	}
+}
+
+func TestRunGraph(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "run.mmd")
+	var stdout, stderr bytes.Buffer
+	if code := run(context.Background(), []string{"run", "-graph", out, writeWorkflow(t, pipeline)}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stdout.String()+stderr.String())
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, expected := range []string{
+		`(?m)^    n1\["shout<br/>succeeded in \S+"\]:::succeeded$`,
+		`(?m)^    classDef succeeded `,
+	} {
+		if !regexp.MustCompile(expected).Match(data) {
+			t.Errorf("Expected graph matching %q, got:\n%s", expected, data)
+		}
+	}
+}
+
+func TestUsage(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"no command", nil},
+		{"unknown command", []string{"deploy"}},
+		{"no file", []string{"run"}},
+		{"unknown flag", []string{"validate", "-x", "workflow.yaml"}},
+		{"unknown format", []string{"graph", "-format", "svg", "workflow.yaml"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(context.Background(), tt.args, &stdout, &stderr); code != exitUsage {
+				t.Errorf("Expected exit code 2, got %d", code)
+			}
+			if !strings.Contains(stderr.String(), "Usage:") {
+				t.Errorf("Expected usage, got %q", stderr.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/josuedeavila/taskflow"
+)
+
+// progress is an Observer printing the lifecycle of the tasks of a run as it
+// happens, followed by a summary.
+type progress struct {
+	mu      sync.Mutex
+	w       io.Writer
+	verbose bool // Print the output of succeeded tasks
+	width   int  // Width of the task name column
+}
+
+func (p *progress) Observe(ctx context.Context, e taskflow.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch e.Type {
+	case taskflow.EventTaskStarted:
+		p.printf(e.Task, "started")
+	case taskflow.EventTaskRetrying:
+		p.printf(e.Task, "attempt %d failed, retrying in %v: %v", e.Attempt, e.Delay, e.Err)
+	case taskflow.EventTaskSucceeded:
+		p.printf(e.Task, "succeeded in %v", round(e.Duration))
+		if s, ok := e.Result.(string); ok && p.verbose && s != "" {
+			indent := strings.Repeat(" ", p.width+2)
+			fmt.Fprintf(p.w, "%s%s\n", indent, strings.ReplaceAll(s, "\n", "\n"+indent))
+		}
+	case taskflow.EventTaskFailed:
+		if e.Attempt > 1 {
+			p.printf(e.Task, "failed after %d attempts in %v: %v", e.Attempt, round(e.Duration), e.Err)
+		} else {
+			p.printf(e.Task, "failed in %v: %v", round(e.Duration), e.Err)
+		}
+	case taskflow.EventTaskCancelled:
+		p.printf(e.Task, "cancelled after %v", round(e.Duration))
+	case taskflow.EventTaskSkipped:
+		p.printf(e.Task, "skipped")
+	case taskflow.EventRunFinished:
+		p.summary(e.Report)
+	}
+}
+
+func (p *progress) printf(task, format string, args ...any) {
+	fmt.Fprintf(p.w, "%-*s  %s\n", p.width, task, fmt.Sprintf(format, args...))
+}
+
+// summary prints the number of tasks per status.
+func (p *progress) summary(report *taskflow.RunReport) {
+	counts := make(map[taskflow.TaskStatus]int)
+	for _, rec := range report.Tasks {
+		counts[rec.Status]++
+	}
+
+	outcome := "succeeded"
+	if !report.Succeeded() {
+		outcome = "failed"
+	}
+	var parts []string
+	for _, status := range []taskflow.TaskStatus{
+		taskflow.StatusSucceeded,
+		taskflow.StatusFailed,
+		taskflow.StatusCancelled,
+		taskflow.StatusSkipped,
+	} {
+		if counts[status] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[status], status))
+		}
+	}
+	fmt.Fprintf(p.w, "run %s %s in %v: %s\n", report.RunID, outcome, round(report.Duration), strings.Join(parts, ", "))
+}
+
+// round shortens durations for display.
+func round(d time.Duration) time.Duration {
+	if d > time.Second {
+		return d.Round(10 * time.Millisecond)
+	}
+	return d.Round(time.Millisecond)
+}
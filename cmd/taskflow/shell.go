@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/josuedeavila/taskflow"
+	"github.com/josuedeavila/taskflow/taskflowdef"
+)
+
+// shellParams are the params of shell tasks.
+type shellParams struct {
+	Command string            `yaml:"command"` // Run by sh -c
+	Dir     string            `yaml:"dir"`     // Working directory, the current one if empty
+	Env     map[string]string `yaml:"env"`     // Added to the environment of the command
+}
+
+// builtins returns the registry of the task kinds the command knows.
+func builtins() *taskflowdef.Registry {
+	registry := taskflowdef.NewRegistry()
+	taskflowdef.RegisterKind(registry, "shell", newShell)
+	return registry
+}
+
+// newShell returns the function of a shell task. The command receives the
+// output of the task's dependencies on its standard input, one per line, and
+// its standard output, without the trailing newline, is the task's output.
+func newShell(p shellParams) (taskflow.TaskFunc[any, string], error) {
+	if p.Command == "" {
+		return nil, errors.New("missing command")
+	}
+	env := os.Environ()
+	for k, v := range p.Env {
+		env = append(env, k+"="+v)
+	}
+
+	return func(ctx context.Context, input any) (string, error) {
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "sh", "-c", p.Command)
+		cmd.Dir = p.Dir
+		cmd.Env = env
+		cmd.Stdin = strings.NewReader(stdin(input))
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		if err := cmd.Run(); err != nil {
+			if msg := lastLine(stderr.String()); msg != "" {
+				return "", fmt.Errorf("%w: %s", err, msg)
+			}
+			return "", err
+		}
+		return strings.TrimSuffix(stdout.String(), "\n"), nil
+	}, nil
+}
+
+// stdin returns the standard input of a shell task receiving input.
+func stdin(input any) string {
+	switch v := input.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []any:
+		lines := make([]string, len(v))
+		for i, item := range v {
+			lines[i] = stdin(item)
+		}
+		return strings.Join(lines, "\n")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// lastLine returns the last non-empty line of s.
+func lastLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
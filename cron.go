@@ -0,0 +1,272 @@
+package taskflow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron is a Trigger firing at the times matching a cron expression in a time
+// zone. Create one with ParseCron.
+type Cron struct {
+	expr string
+	loc  *time.Location
+
+	second, minute, hour, dom, month, dow uint64 // Bit i is set when value i matches
+	domStar, dowStar                      bool
+	elapsed                               bool // Fires on elapsed time across daylight saving time changes
+}
+
+// cronField describes a field of a cron expression.
+type cronField struct {
+	name     string
+	min, max int
+	names    []string // Names of the values from min, if any
+}
+
+var (
+	secondField = cronField{name: "second", min: 0, max: 59}
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	domField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12,
+		names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}}
+	dowField = cronField{name: "day of week", min: 0, max: 7,
+		names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}}
+)
+
+// cronMacros are the supported shorthands for common expressions.
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron parses a standard cron expression evaluated in loc. The expression
+// has five fields (minute, hour, day of month, month and day of week) or six
+// with a leading second field. Fields accept *, ?, values, ranges (1-5), lists
+// (1,3,5) and steps (*/15 or 10-30/5); months and days of week also accept
+// three letter names (JAN, MON), and Sunday is 0 or 7. When both the day of
+// month and the day of week are restricted, a day matching either matches.
+// The @yearly, @monthly, @weekly, @daily and @hourly shorthands are accepted.
+//
+// Daylight saving time changes are handled like Vixie cron does. Expressions
+// with a wildcard (* or ?) second, minute or hour, such as "*/30 * * * *" or
+// "@hourly", follow elapsed time: they keep firing through an hour repeated
+// when clocks go back, and times skipped when clocks go forward are just
+// skipped. The others fire once for each matching wall clock time: a repeated
+// time fires the first time it occurs, and a skipped time fires as the clocks
+// change.
+func ParseCron(expr string, loc *time.Location) (*Cron, error) {
+	if loc == nil {
+		return nil, fmt.Errorf("taskflow: cron expression %q: no time zone", expr)
+	}
+
+	spec := strings.TrimSpace(expr)
+	if macro, ok := cronMacros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("taskflow: cron expression %q: expected 5 or 6 fields, got %d", expr, len(fields))
+	}
+
+	c := &Cron{expr: expr, loc: loc}
+	var err error
+	parse := func(dst *uint64, field string, f cronField) {
+		if err != nil {
+			return
+		}
+		if *dst, err = f.parse(field); err != nil {
+			err = fmt.Errorf("taskflow: cron expression %q: %s: %w", expr, f.name, err)
+		}
+	}
+	parse(&c.second, fields[0], secondField)
+	parse(&c.minute, fields[1], minuteField)
+	parse(&c.hour, fields[2], hourField)
+	parse(&c.dom, fields[3], domField)
+	parse(&c.month, fields[4], monthField)
+	parse(&c.dow, fields[5], dowField)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sunday is both 0 and 7
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.domStar = fields[3] == "*" || fields[3] == "?"
+	c.dowStar = fields[5] == "*" || fields[5] == "?"
+	for _, field := range fields[:3] {
+		c.elapsed = c.elapsed || strings.HasPrefix(field, "*") || strings.HasPrefix(field, "?")
+	}
+	return c, nil
+}
+
+// parse returns the set of values matched by a field.
+func (f cronField) parse(field string) (uint64, error) {
+	var set uint64
+	for item := range strings.SplitSeq(field, ",") {
+		lo, hi, step := f.min, f.max, 1
+
+		rng, stepText, hasStep := strings.Cut(item, "/")
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepText)
+			}
+			step = n
+		}
+
+		if rng != "*" && rng != "?" {
+			first, last, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = f.value(first); err != nil {
+				return 0, err
+			}
+			switch {
+			case isRange:
+				if hi, err = f.value(last); err != nil {
+					return 0, err
+				}
+				if hi < lo {
+					return 0, fmt.Errorf("invalid range %q", rng)
+				}
+			case !hasStep:
+				hi = lo
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// value parses a single value of the field.
+func (f cronField) value(text string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(text, name) {
+			return f.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", text)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next implements Trigger. It returns the zero time if the expression doesn't
+// match within the next five years, like "0 0 30 2 *".
+func (c *Cron) Next(after time.Time) time.Time {
+	after = after.In(c.loc)
+	if c.elapsed {
+		return c.next(after, after.AddDate(5, 0, 0))
+	}
+
+	// Fixed times are found on the wall clock, represented in UTC so that
+	// they don't skip or repeat, and then located in time.
+	wall := wallTime(after)
+	limit := wall.AddDate(5, 0, 0)
+	for {
+		if wall = c.next(wall, limit); wall.IsZero() {
+			return time.Time{}
+		}
+		// The first occurrence of a repeated time may be before after.
+		if t := c.instant(wall); t.After(after) {
+			return t
+		}
+	}
+}
+
+// next returns the first time matching the expression after t in the location
+// of t, or the zero time if there is none before limit.
+func (c *Cron) next(t, limit time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Second).Add(time.Second)
+	for t.Before(limit) {
+		year, month, day := t.Date()
+		switch {
+		case c.month&(1<<month) == 0:
+			t = time.Date(year, month+1, 1, 0, 0, 0, 0, loc)
+		case !c.matchDay(t):
+			t = time.Date(year, month, day+1, 0, 0, 0, 0, loc)
+		// Within a day, time moves by absolute durations so that it never
+		// goes back across daylight saving time changes.
+		case c.hour&(1<<t.Hour()) == 0:
+			t = t.Truncate(time.Minute).Add(time.Duration(60-t.Minute()) * time.Minute)
+		case c.minute&(1<<t.Minute()) == 0:
+			t = t.Truncate(time.Minute).Add(time.Minute)
+		case c.second&(1<<t.Second()) == 0:
+			t = t.Add(time.Second)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// instant returns the first time the clocks of the location show wall, or the
+// time they went forward past it if they never do.
+func (c *Cron) instant(wall time.Time) time.Time {
+	// Offsets are within 14 hours of UTC, so wall happens between these two
+	// instants, and for all practical purposes at most one change in between.
+	_, before := wall.Add(-14 * time.Hour).In(c.loc).Zone()
+	_, after := wall.Add(14 * time.Hour).In(c.loc).Zone()
+	first := wall.Add(-time.Duration(before) * time.Second).In(c.loc)
+	second := wall.Add(-time.Duration(after) * time.Second).In(c.loc)
+	if second.Before(first) {
+		first, second = second, first
+	}
+
+	switch {
+	case wallTime(first).Equal(wall):
+		return first
+	case wallTime(second).Equal(wall):
+		return second
+	}
+	// Skipped: second is past the change, in the zone starting with it.
+	start, _ := second.ZoneBounds()
+	return start
+}
+
+// wallTime returns the date and time shown by the clocks at t, in UTC.
+func wallTime(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
+
+// matchDay reports whether the day of t matches the day of month and day of
+// week fields.
+func (c *Cron) matchDay(t time.Time) bool {
+	dom := c.dom&(1<<t.Day()) != 0
+	dow := c.dow&(1<<t.Weekday()) != 0
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// Location returns the time zone the expression is evaluated in.
+func (c *Cron) Location() *time.Location {
+	return c.loc
+}
+
+// String returns the expression.
+func (c *Cron) String() string {
+	return c.expr
+}
@@ -0,0 +1,161 @@
+package taskflow_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josuedeavila/taskflow"
+)
+
+func TestCronNext(t *testing.T) {
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skipf("Time zone database unavailable: %v", err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("Time zone database unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		expr     string
+		loc      *time.Location
+		after    time.Time
+		expected time.Time
+	}{
+		{"every minute", "* * * * *", time.UTC,
+			time.Date(2026, 3, 10, 8, 30, 15, 0, time.UTC), time.Date(2026, 3, 10, 8, 31, 0, 0, time.UTC)},
+		{"daily", "0 3 * * *", time.UTC,
+			time.Date(2026, 3, 10, 8, 30, 0, 0, time.UTC), time.Date(2026, 3, 11, 3, 0, 0, 0, time.UTC)},
+		{"step", "*/15 * * * *", time.UTC,
+			time.Date(2026, 3, 10, 8, 31, 0, 0, time.UTC), time.Date(2026, 3, 10, 8, 45, 0, 0, time.UTC)},
+		{"range with step", "10-30/10 9 * * *", time.UTC,
+			time.Date(2026, 3, 10, 9, 21, 0, 0, time.UTC), time.Date(2026, 3, 10, 9, 30, 0, 0, time.UTC)},
+		{"seconds", "*/20 * * * * *", time.UTC,
+			time.Date(2026, 3, 10, 8, 30, 41, 0, time.UTC), time.Date(2026, 3, 10, 8, 31, 0, 0, time.UTC)},
+		{"names", "0 12 * FEB MON-FRI", time.UTC,
+			time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 2, 12, 0, 0, 0, time.UTC)},
+		{"sunday as 7", "0 0 * * 7", time.UTC,
+			time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"day of month or week", "0 0 13 * FRI", time.UTC,
+			time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC)},
+		{"leap day", "0 0 29 2 *", time.UTC,
+			time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"macro", "@hourly", time.UTC,
+			time.Date(2026, 3, 10, 8, 30, 0, 0, time.UTC), time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)},
+		{"time zone", "0 9 * * *", saoPaulo,
+			time.Date(2026, 3, 10, 11, 0, 0, 0, time.UTC), time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)},
+		{"after daylight saving time", "0 3 * * *", newYork,
+			time.Date(2026, 3, 8, 0, 0, 0, 0, newYork), time.Date(2026, 3, 8, 3, 0, 0, 0, newYork)},
+		{"never", "0 0 30 2 *", time.UTC,
+			time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cron, err := taskflow.ParseCron(tt.expr, tt.loc)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if next := cron.Next(tt.after); !next.Equal(tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, next)
+			}
+		})
+	}
+}
+
+func TestCronDaylightSavingTime(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("Time zone database unavailable: %v", err)
+	}
+	// Clocks go forward from 02:00 EST to 03:00 EDT on 2026-03-08, and back
+	// from 02:00 EDT to 01:00 EST on 2026-11-01.
+	edt := time.FixedZone("EDT", -4*60*60)
+	est := time.FixedZone("EST", -5*60*60)
+
+	tests := []struct {
+		name     string
+		expr     string
+		after    time.Time
+		expected []time.Time
+	}{
+		{"skipped time runs as clocks go forward", "30 2 * * *",
+			time.Date(2026, 3, 8, 0, 0, 0, 0, est),
+			[]time.Time{time.Date(2026, 3, 8, 3, 0, 0, 0, edt), time.Date(2026, 3, 9, 2, 30, 0, 0, edt)}},
+		{"skipped times run once", "0,20,40 2 * * *",
+			time.Date(2026, 3, 8, 1, 50, 0, 0, est),
+			[]time.Time{time.Date(2026, 3, 8, 3, 0, 0, 0, edt), time.Date(2026, 3, 9, 2, 0, 0, 0, edt)}},
+		{"repeated time runs once", "30 1 * * *",
+			time.Date(2026, 11, 1, 0, 0, 0, 0, edt),
+			[]time.Time{time.Date(2026, 11, 1, 1, 30, 0, 0, edt), time.Date(2026, 11, 2, 1, 30, 0, 0, est)}},
+		{"during the repeated hour", "30 1 * * *",
+			time.Date(2026, 11, 1, 1, 10, 0, 0, est),
+			[]time.Time{time.Date(2026, 11, 2, 1, 30, 0, 0, est)}},
+		{"wildcard skips nothing as clocks go forward", "*/30 * * * *",
+			time.Date(2026, 3, 8, 1, 0, 0, 0, est),
+			[]time.Time{time.Date(2026, 3, 8, 1, 30, 0, 0, est), time.Date(2026, 3, 8, 3, 0, 0, 0, edt), time.Date(2026, 3, 8, 3, 30, 0, 0, edt)}},
+		{"wildcard minute in a skipped hour", "*/20 2 * * *",
+			time.Date(2026, 3, 8, 1, 50, 0, 0, est),
+			[]time.Time{time.Date(2026, 3, 9, 2, 0, 0, 0, edt)}},
+		{"wildcard hour runs through the repeated hour", "@hourly",
+			time.Date(2026, 11, 1, 0, 30, 0, 0, edt),
+			[]time.Time{
+				time.Date(2026, 11, 1, 1, 0, 0, 0, edt),
+				time.Date(2026, 11, 1, 1, 0, 0, 0, est),
+				time.Date(2026, 11, 1, 2, 0, 0, 0, est),
+			}},
+		{"wildcard minute runs through the repeated hour", "*/30 * * * *",
+			time.Date(2026, 11, 1, 1, 15, 0, 0, edt),
+			[]time.Time{
+				time.Date(2026, 11, 1, 1, 30, 0, 0, edt),
+				time.Date(2026, 11, 1, 1, 0, 0, 0, est),
+				time.Date(2026, 11, 1, 1, 30, 0, 0, est),
+				time.Date(2026, 11, 1, 2, 0, 0, 0, est),
+			}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cron, err := taskflow.ParseCron(tt.expr, newYork)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			next := tt.after
+			for _, expected := range tt.expected {
+				if next = cron.Next(next); !next.Equal(expected) {
+					t.Fatalf("Expected %v, got %v", expected, next)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		loc      *time.Location
+		expected string
+	}{
+		{"no time zone", "* * * * *", nil, "no time zone"},
+		{"too few fields", "* * * *", time.UTC, "expected 5 or 6 fields, got 4"},
+		{"too many fields", "* * * * * * *", time.UTC, "expected 5 or 6 fields, got 7"},
+		{"out of range", "60 * * * *", time.UTC, "minute: value 60 out of range [0, 59]"},
+		{"invalid value", "* * * JANUARY *", time.UTC, `month: invalid value "JANUARY"`},
+		{"invalid step", "*/0 * * * *", time.UTC, `minute: invalid step "0"`},
+		{"invalid range", "* 5-1 * * *", time.UTC, `hour: invalid range "5-1"`},
+		{"empty item", "1,,2 * * * *", time.UTC, `minute: invalid value ""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := taskflow.ParseCron(tt.expr, tt.loc)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
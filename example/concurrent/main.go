@@ -9,21 +9,9 @@ import (
 	"github.com/josuedeavila/taskflow"
 )
 
-type slogLogger struct {
-	*slog.Logger
-}
-
-func (l *slogLogger) Log(v ...any) {
-	slog.Info("TaskFlow Log", "message", fmt.Sprint(v...))
-}
-
-func newSlogLogger() *slogLogger {
-	l := slog.Default()
-	return &slogLogger{Logger: l}
-}
-
 func main() {
-	logger := newSlogLogger()
+	logger := slog.Default()
+	taskLogger := taskflow.NewSlogLogger(logger) // Logs task name, run ID and attempt as attributes
 
 	// 1. Creating some simple task functions
 	taskFn1 := func(ctx context.Context, _ any) (string, error) {
@@ -52,7 +40,7 @@ func main() {
 		}
 	}
 
-	taskFn3 := func(ctx context.Context, input any) (string, error) {
+	taskFn3 := func(ctx context.Context, users string, products string) (string, error) {
 		logger.Info("Executing Task 3: Generating report (depends on Task 1 and Task 2)...")
 		time.Sleep(1500 * time.Millisecond) // Simulates work
 		select {
@@ -60,7 +48,7 @@ func main() {
 			logger.Info("Task 3 cancelled!")
 			return "", ctx.Err()
 		default:
-			logger.Info(fmt.Sprintf("Task 3 completed with input: %v", input))
+			logger.Info(fmt.Sprintf("Task 3 completed with inputs: %s, %s", users, products))
 			return "final_report", nil
 		}
 	}
@@ -78,10 +66,10 @@ func main() {
 	}
 
 	// 2. Creating the tasks
-	task1 := taskflow.NewTask("FetchUsers", taskFn1).WithLogger(logger) // Adds a default logger
-	task2 := taskflow.NewTask("ProcessProducts", taskFn2).WithLogger(logger)
-	task3 := taskflow.NewTask("GenerateReport", taskFn3).WithLogger(logger).After(task1, task2) // Task 3 depends on Task 1 and Task 2
-	taskError := taskflow.NewTask("SimulateError", taskFnError).WithLogger(logger)
+	task1 := taskflow.NewTask("FetchUsers", taskFn1).WithLogger(taskLogger) // Replaces the default logger
+	task2 := taskflow.NewTask("ProcessProducts", taskFn2).WithLogger(taskLogger)
+	task3 := taskflow.Join2("GenerateReport", task1, task2, taskFn3).WithLogger(taskLogger) // Task 3 receives the results of Task 1 and Task 2
+	taskError := taskflow.NewTask("SimulateError", taskFnError).WithLogger(taskLogger)
 
 	// 3. Creating a FanOutTask
 	fanOutGenerateFunc := func(ctx context.Context, _ []any) ([]taskflow.TaskFunc[any, float64], error) {
@@ -126,7 +114,7 @@ func main() {
 	fanOutConvertedTask := fanOutTask.ToTask()
 
 	// 4. Creating the Runner and adding the tasks
-	runner := taskflow.NewRunner()
+	runner := taskflow.NewRunner().WithErrorMode(taskflow.ContinueOnError) // Keeps running when SimulateError fails
 	runner.Add(task1, task2, task3, taskError, fanOutConvertedTask)
 
 	// 5. Running the tasks with a context that can be cancelled
@@ -134,7 +122,7 @@ func main() {
 	defer cancel()
 
 	logger.Info("Running the Runner...")
-	err := runner.Run(ctx)
+	report, err := runner.Run(ctx)
 
 	if err != nil {
 		logger.Info(fmt.Sprintf("Runner completed with error: %v", err))
@@ -144,13 +132,8 @@ func main() {
 
 	// 6. Checking the results and states of the tasks
 	logger.Info("Checking task results:")
-	logger.Info(fmt.Sprintf("Task 'FetchUsers' - Result: %v, Error: %v", task1.Result, task1.Err))
-	logger.Info(fmt.Sprintf("Task 'ProcessProducts' - Result: %v, Error: %v", task2.Result, task2.Err))
-	logger.Info(fmt.Sprintf("Task 'GenerateReport' - Result: %v, Error: %v", task3.Result, task3.Err))
-	logger.Info(fmt.Sprintf("Task 'SimulateError' - Result: %v, Error: %v", taskError.Result, taskError.Err))
-	logger.Info(fmt.Sprintf("Task 'CalculateMetrics' - Result: %v, Error: %v", fanOutConvertedTask.Result, fanOutConvertedTask.Err))
-
-	// Example of how you can see the state of a task (after execution)
-	// This would require exposing the 'state' field or a method to get it in the Task struct.
-	// For now, the `logger.Log` inside the states already shows the transition.
+	for _, rec := range report.Tasks {
+		logger.Info("Task record", "task", rec.Name, "status", rec.Status,
+			"duration", rec.Duration.Round(time.Millisecond), "result", rec.Result, "error", rec.Err)
+	}
 }
@@ -10,21 +10,9 @@ import (
 	"github.com/josuedeavila/taskflow"
 )
 
-type slogLogger struct {
-	*slog.Logger
-}
-
-func (l *slogLogger) Log(v ...any) {
-	slog.Info("TaskFlow Log", "message", fmt.Sprint(v...))
-}
-
-func newSlogLogger() *slogLogger {
-	l := slog.Default()
-	return &slogLogger{Logger: l}
-}
-
 func main() {
-	logger := newSlogLogger()
+	logger := slog.Default()
+	taskLogger := taskflow.NewSlogLogger(logger) // Logs task name, run ID and attempt as attributes
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -34,60 +22,66 @@ func main() {
 		"https://httpbin.org/get",
 	}
 
-	fan := &taskflow.FanOutTask[any, map[string]any]{
-		Name: "check_public_apis",
-		Generate: func(ctx context.Context, _ []any) ([]taskflow.TaskFunc[any, map[string]any], error) {
-			var fns []taskflow.TaskFunc[any, map[string]any]
-			for _, url := range apis {
-				url := url
-				fns = append(fns, func(ctx context.Context, _ any) (map[string]any, error) {
-					req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-					if err != nil {
-						return map[string]any{"url": url, "status": "request-error", "err": err.Error()}, nil
-					}
-					resp, err := http.DefaultClient.Do(req)
-					if err != nil {
-						return map[string]any{"url": url, "status": "timeout", "err": err.Error()}, nil
-					}
-					defer resp.Body.Close()
-					logger.Info(fmt.Sprintf("✔️ Fetched from: %s,  Status: %v", url, resp.StatusCode))
-					return map[string]any{
-						"url":    url,
-						"status": resp.StatusCode,
-					}, nil
-				})
+	listAPIs := taskflow.NewTask("list_public_apis", func(ctx context.Context, _ any) ([]string, error) {
+		return apis, nil
+	}).WithLogger(taskLogger)
+
+	fan := &taskflow.FanOutTask[string, map[string]any]{
+		Name:           "check_public_apis",
+		MaxConcurrency: 2,
+		// Map is called once per URL returned by list_public_apis
+		Map: func(ctx context.Context, url string) (map[string]any, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			if err != nil {
+				return nil, err
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return nil, err
 			}
-			return fns, nil
+			defer resp.Body.Close()
+			logger.Info("✔️ Fetched", "url", url, "status", resp.StatusCode)
+			return map[string]any{
+				"url":    url,
+				"status": resp.StatusCode,
+			}, nil
 		},
 
-		FanIn: func(ctx context.Context, input []map[string]any) (map[string]any, error) {
-			results := input
+		// Failed requests are handed to the fan-in along with the responses,
+		// as long as at least two thirds of the APIs answered.
+		MinSuccessRatio: 0.66,
+		FanInResults: func(ctx context.Context, results []taskflow.Result[map[string]any]) (map[string]any, error) {
 			statusCounts := make(map[string]int)
 			for _, r := range results {
-				status := fmt.Sprintf("%v", r["status"])
+				if r.Err != nil {
+					logger.Info("❌ Request failed", "url", apis[r.Index], "error", r.Err)
+					statusCounts["error"]++
+					continue
+				}
+				status := fmt.Sprintf("%v", r.Value["status"])
 				statusCounts[status]++
 			}
 
 			logger.Info("📊 Resume of status:")
 			for k, v := range statusCounts {
-				logger.Info(fmt.Sprintf("  %s: %d responses\n", k, v))
+				logger.Info("  responses", "status", k, "count", v)
 			}
 			return nil, nil
 		},
 	}
 
-	aggregate := fan.ToTask()
+	aggregate := fan.ToTask().WithLogger(taskLogger).After(listAPIs)
 
 	logTask := taskflow.NewTask("log_summary", func(ctx context.Context, input any) (any, error) {
 		logger.Info("✅ Done.")
 		return nil, nil
-	}).After(aggregate)
+	}).WithLogger(taskLogger).After(aggregate)
 
 	runner := taskflow.NewRunner()
 	runner.Add(logTask)
 
 	// Executa
-	if err := runner.Run(ctx); err != nil {
-		logger.Error(fmt.Sprintf("❌ Error: %s", err))
+	if _, err := runner.Run(ctx); err != nil {
+		logger.Error("❌ Run failed", "error", err)
 	}
 }
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"sync"
 	"time"
 
 	"github.com/josuedeavila/taskflow"
@@ -19,97 +18,28 @@ const (
 type ProcessingConfig struct {
 	InteractionType InteractionType
 	ProcessInterval time.Duration
-	MaxConcurrency  int
 	MaxRetries      int
 	RetryDelay      time.Duration
 }
 
-type MinimalOrchestrator struct {
-	configs    map[InteractionType]*ProcessingConfig
-	semaphores map[InteractionType]chan struct{}
-	shutdown   chan struct{}
-	wg         sync.WaitGroup
-}
-
-func NewMinimalOrchestrator() *MinimalOrchestrator {
-	return &MinimalOrchestrator{
-		configs:    make(map[InteractionType]*ProcessingConfig),
-		semaphores: make(map[InteractionType]chan struct{}),
-		shutdown:   make(chan struct{}),
-	}
-}
-
-func (o *MinimalOrchestrator) AddConfig(config *ProcessingConfig) {
-	o.configs[config.InteractionType] = config
-	o.semaphores[config.InteractionType] = make(chan struct{}, config.MaxConcurrency)
-}
-
-func (o *MinimalOrchestrator) Start(ctx context.Context) {
-	for t, config := range o.configs {
-		o.wg.Add(1)
-		go o.runLoop(ctx, t, config)
-	}
-}
-
-func (o *MinimalOrchestrator) runLoop(ctx context.Context, interactionType InteractionType, config *ProcessingConfig) {
-	defer o.wg.Done()
-	ticker := time.NewTicker(config.ProcessInterval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-o.shutdown:
-			return
-		case <-ticker.C:
-			o.semaphores[interactionType] <- struct{}{}
-			err := o.execute(ctx, interactionType, config)
-			<-o.semaphores[interactionType]
-
-			if err != nil {
-				log.Printf("⚠️ Task failed after all retries: %v", err)
-			}
-		}
+// newSchedule builds the workflow once; every tick of the schedule runs a new
+// execution of it.
+func newSchedule(config *ProcessingConfig) (*taskflow.Schedule, error) {
+	retry := taskflow.RetryPolicy{
+		MaxRetries: config.MaxRetries,
+		Strategy:   taskflow.ConstantBackoff,
+		BaseDelay:  config.RetryDelay,
+		OnRetry: func(attempt int, delay time.Duration, err error) {
+			log.Printf("❌ Attempt %d failed: %v", attempt-1, err)
+			log.Printf("⏳ Waiting %s before retry...", delay)
+		},
 	}
-}
-
-func (o *MinimalOrchestrator) execute(ctx context.Context, interactionType InteractionType, config *ProcessingConfig) error {
-	var err error
-	var result any
-
-	for attempt := 1; attempt <= config.MaxRetries+1; attempt++ {
-		log.Printf("🚀 Executing task (%d/%d): %s", attempt, config.MaxRetries+1, interactionType)
-
-		jobCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-		result, err = o.runPipeline(jobCtx, interactionType)
-		cancel()
-
-		if err == nil {
-			log.Printf("✅ Task completed: %+v", result)
-			return nil
-		}
-
-		log.Printf("❌ Attempt %d failed: %v", attempt, err)
-
-		if attempt <= config.MaxRetries {
-			log.Printf("⏳ Waiting %s before retry...", config.RetryDelay)
-			time.Sleep(config.RetryDelay)
-		}
-	}
-
-	log.Printf("🛑 All attempts failed for %s", interactionType)
-	return err
-}
-
-func (o *MinimalOrchestrator) runPipeline(ctx context.Context, interactionType InteractionType) (any, error) {
-	var finalResult any
 
 	fetch := taskflow.NewTask("fetch", func(ctx context.Context, _ any) ([]string, error) {
-		log.Printf("🔍 Fetching events for %s", interactionType)
+		log.Printf("🔍 Fetching events for %s", config.InteractionType)
 		time.Sleep(500 * time.Millisecond)
 		return []string{"evt1", "evt2"}, nil
-	}).WithLogger(&taskflow.NoOpLogger{})
+	}).WithLogger(&taskflow.NoOpLogger{}).WithTimeout(5 * time.Second).WithRetry(retry)
 
 	process := taskflow.NewTask("process", func(ctx context.Context, input []string) (map[string]int, error) {
 		log.Printf("⚙️ Processing %d events", len(input))
@@ -121,48 +51,50 @@ func (o *MinimalOrchestrator) runPipeline(ctx context.Context, interactionType I
 
 		result := map[string]int{"processed": len(input)}
 		return result, nil
-	}).WithLogger(&taskflow.NoOpLogger{}).After(fetch)
-
-	capture := taskflow.NewTask("capture", func(ctx context.Context, input map[string]int) (any, error) {
-		finalResult = input
-		log.Printf("📦 Result captured: %+v", finalResult)
-		return nil, nil
-	}).WithLogger(&taskflow.NoOpLogger{}).After(process)
+	}).WithLogger(&taskflow.NoOpLogger{}).WithTimeout(5 * time.Second).WithRetry(retry).After(fetch)
 
-	runner := taskflow.NewRunner()
-	runner.Add(capture)
-
-	err := runner.Run(ctx)
-	return finalResult, err
-}
+	workflow, err := taskflow.NewWorkflow(process)
+	if err != nil {
+		return nil, err
+	}
 
-func (o *MinimalOrchestrator) Shutdown() {
-	log.Println("🛑 Shutting down orchestrator...")
-	close(o.shutdown)
-	o.wg.Wait()
-	log.Println("✅ Shutdown complete")
+	// Runs taking longer than the interval delay the next one instead of overlapping
+	return taskflow.Every(string(config.InteractionType), config.ProcessInterval, workflow).
+		WithOverlap(taskflow.QueueOverlap).
+		WithOnComplete(func(report *taskflow.RunReport, err error) {
+			if err != nil {
+				log.Printf("🛑 All attempts failed for %s: %v", config.InteractionType, err)
+				return
+			}
+			rec, _ := report.Task("process")
+			log.Printf("✅ Task completed: %+v", rec.Result)
+		}), nil
 }
 
 func main() {
-	orchestrator := NewMinimalOrchestrator()
-
-	orchestrator.AddConfig(&ProcessingConfig{
+	schedule, err := newSchedule(&ProcessingConfig{
 		InteractionType: OfferUpdate,
 		ProcessInterval: 3 * time.Second,
-		MaxConcurrency:  1,
 		MaxRetries:      3,
 		RetryDelay:      2 * time.Second,
 	})
+	if err != nil {
+		log.Fatalf("invalid pipeline: %v", err)
+	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	orchestrator.Start(ctx)
+	scheduler := taskflow.NewScheduler().WithLogger(&taskflow.NoOpLogger{})
+	scheduler.Add(schedule)
+	if err := scheduler.Start(context.Background()); err != nil {
+		log.Fatalf("invalid schedule: %v", err)
+	}
 
-	time.AfterFunc(20*time.Second, func() {
-		cancel()
-		orchestrator.Shutdown()
-	})
+	time.Sleep(20 * time.Second)
 
-	<-ctx.Done()
+	log.Println("🛑 Shutting down scheduler...")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := scheduler.Stop(ctx); err != nil {
+		log.Printf("⚠️ Runs cancelled before completing: %v", err)
+	}
+	log.Println("✅ Shutdown complete")
 }
@@ -0,0 +1,310 @@
+package taskflow
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// execution holds the state of a single run, shared by every task in it
+// through the context. It runs each task at most once and records the outcome.
+type execution struct {
+	id        string
+	observers []Observer // Notified of the events of every task in the run
+	mu        sync.Mutex
+	states    map[Executable]*taskState
+	queued    map[Executable]time.Time // When tasks waiting for a worker became ready
+	store     StateStore               // Persists the outcome of tasks, if set
+	restored  map[string]TaskState     // States of a resumed run, by task name
+}
+
+// executor is implemented by executables that run within an execution, such
+// as Task, whose Run method starts a new execution when called on its own.
+type executor interface {
+	execute(ctx context.Context, input any) (result any, attempts int, err error)
+}
+
+// taskState is the state of a task within an execution.
+type taskState struct {
+	done      chan struct{}
+	ctx       context.Context // Context the task started with, see execution.startTask
+	queueWait time.Duration   // Time the task waited for a worker once ready
+	record    TaskRecord
+}
+
+type executionKey struct{}
+
+func newExecution(observers ...Observer) *execution {
+	return &execution{
+		id:        newRunID(),
+		observers: observers,
+		states:    make(map[Executable]*taskState),
+		queued:    make(map[Executable]time.Time),
+	}
+}
+
+// enqueue records that e became ready to run at the given time and waits for a worker.
+func (x *execution) enqueue(e Executable, readyAt time.Time) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.queued[e] = readyAt
+}
+
+// newRunID returns a random identifier for an execution.
+func newRunID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// withExecution returns a context carrying x.
+func withExecution(ctx context.Context, x *execution) context.Context {
+	return context.WithValue(ctx, executionKey{}, x)
+}
+
+// executionFrom returns the execution carried by ctx, if any.
+func executionFrom(ctx context.Context) *execution {
+	x, _ := ctx.Value(executionKey{}).(*execution)
+	return x
+}
+
+// runExecutable runs e within the execution carried by ctx, if any, so that
+// it runs once per execution and its outcome is recorded.
+func runExecutable(ctx context.Context, e Executable, input any) (any, error) {
+	if x := executionFrom(ctx); x != nil {
+		return x.run(ctx, e, input)
+	}
+	return e.Run(ctx, input)
+}
+
+// run executes e, or waits for the execution of e already in progress and
+// returns its outcome.
+func (x *execution) run(ctx context.Context, e Executable, input any) (any, error) {
+	now := time.Now()
+	x.mu.Lock()
+	st, started := x.states[e]
+	if !started {
+		st = &taskState{done: make(chan struct{})}
+		if readyAt, ok := x.queued[e]; ok {
+			st.queueWait = now.Sub(readyAt)
+			delete(x.queued, e)
+		}
+		x.states[e] = st
+	}
+	x.mu.Unlock()
+
+	if started {
+		select {
+		case <-st.done:
+			return st.record.Result, st.record.Err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if record, ok := x.restore(e); ok {
+		if inlinesDependencies(e) {
+			// The chain doesn't run again, and neither do the tasks it runs.
+			x.restoreInline(ctx, e, now)
+		}
+		x.finishRestored(ctx, e, st, record, now)
+		return record.Result, nil
+	}
+
+	start := now
+	var result any
+	var err error
+	attempts := 1
+	if ex, ok := e.(executor); ok {
+		// Executors report their own start, once their dependencies completed.
+		result, attempts, err = ex.execute(ctx, input)
+	} else {
+		result, err = e.Run(x.startTask(ctx, e, Event{Time: start, Input: input}), input)
+	}
+	end := time.Now()
+
+	x.mu.Lock()
+	record := TaskRecord{
+		Name:     nodeName(e),
+		Status:   x.status(ctx, e, err),
+		Start:    start,
+		End:      end,
+		Duration: end.Sub(start),
+		Attempts: attempts,
+		Err:      err,
+		Result:   result,
+	}
+	x.mu.Unlock()
+
+	if cerr := x.checkpoint(ctx, record); cerr != nil {
+		// A task whose outcome isn't persisted would be lost on resume.
+		record.Status, record.Err, record.Result = StatusFailed, cerr, nil
+		result, err = nil, cerr
+	}
+
+	x.mu.Lock()
+	st.record = record
+	if st.ctx != nil {
+		ctx = st.ctx
+	}
+	x.mu.Unlock()
+
+	x.emit(ctx, e, Event{
+		Type:     eventFor(record.Status),
+		Time:     end,
+		Start:    start,
+		Duration: record.Duration,
+		Attempt:  attempts,
+		Err:      err,
+		Result:   result,
+	})
+	close(st.done)
+
+	return result, err
+}
+
+// checkpoint persists the outcome of a task to the run's store, if any. It keeps
+// going when the run is cancelled, so that completed work isn't lost.
+func (x *execution) checkpoint(ctx context.Context, record TaskRecord) error {
+	if x.store == nil {
+		return nil
+	}
+	if err := x.store.SaveTask(context.WithoutCancel(ctx), x.id, newTaskState(record)); err != nil {
+		return fmt.Errorf("taskflow: checkpoint task %s: %w", record.Name, err)
+	}
+	return nil
+}
+
+// restore returns the record of e if it succeeded in the run being resumed and
+// its result was stored and can be decoded. Other tasks run again.
+func (x *execution) restore(e Executable) (TaskRecord, bool) {
+	state, ok := x.restored[nodeName(e)]
+	if !ok || state.Status != StatusSucceeded || len(state.Result) == 0 {
+		return TaskRecord{}, false
+	}
+	r, ok := e.(restorer)
+	if !ok {
+		return TaskRecord{}, false
+	}
+	result, err := r.restoreResult(state.Result)
+	if err != nil {
+		return TaskRecord{}, false
+	}
+	return TaskRecord{
+		Name:     state.Task,
+		Status:   StatusSucceeded,
+		Start:    state.Start,
+		End:      state.End,
+		Duration: state.End.Sub(state.Start),
+		Attempts: state.Attempts,
+		Result:   result,
+		Restored: true,
+	}, true
+}
+
+// finishRestored records the restored outcome of e and notifies the observers.
+func (x *execution) finishRestored(ctx context.Context, e Executable, st *taskState, record TaskRecord, now time.Time) {
+	x.mu.Lock()
+	st.record = record
+	x.mu.Unlock()
+	x.emit(ctx, e, Event{
+		Type:    EventTaskRestored,
+		Time:    now,
+		Start:   record.Start,
+		Attempt: record.Attempts,
+		Result:  record.Result,
+	})
+	close(st.done)
+}
+
+// restoreInline records the dependencies e runs itself as restored, dependencies
+// first. They succeeded along with e, so they are restored even if their own
+// result can't be decoded, since nothing needs it.
+func (x *execution) restoreInline(ctx context.Context, e Executable, now time.Time) {
+	for _, dep := range dependenciesOf(e) {
+		x.mu.Lock()
+		st, started := x.states[dep]
+		if !started {
+			st = &taskState{done: make(chan struct{})}
+			x.states[dep] = st
+		}
+		x.mu.Unlock()
+		if started {
+			continue
+		}
+
+		x.restoreInline(ctx, dep, now)
+		record, ok := x.restore(dep)
+		if !ok {
+			state := x.restored[nodeName(dep)]
+			record = TaskRecord{
+				Name:     nodeName(dep),
+				Status:   StatusSucceeded,
+				Start:    state.Start,
+				End:      state.End,
+				Duration: state.End.Sub(state.Start),
+				Attempts: state.Attempts,
+				Restored: true,
+			}
+		}
+		x.finishRestored(ctx, dep, st, record, now)
+	}
+}
+
+// status classifies the outcome of e. It must be called with x.mu held.
+func (x *execution) status(ctx context.Context, e Executable, err error) TaskStatus {
+	if err == nil {
+		return StatusSucceeded
+	}
+	for _, dep := range dependenciesOf(e) {
+		if st, ok := x.states[dep]; ok && st.record.Err != nil && errors.Is(err, st.record.Err) {
+			return StatusSkipped
+		}
+	}
+	if ctx.Err() != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		return StatusCancelled
+	}
+	return StatusFailed
+}
+
+// skipUnstarted records the tasks of g that never ran as skipped and notifies
+// the observers.
+func (x *execution) skipUnstarted(ctx context.Context, g *Graph) {
+	var skipped []Executable
+	x.mu.Lock()
+	for _, n := range g.Nodes() {
+		if _, ok := x.states[n]; !ok {
+			st := &taskState{done: make(chan struct{})}
+			st.record = TaskRecord{Name: nodeName(n), Status: StatusSkipped}
+			close(st.done)
+			x.states[n] = st
+			skipped = append(skipped, n)
+		}
+	}
+	x.mu.Unlock()
+
+	for _, n := range skipped {
+		x.emit(ctx, n, Event{Type: EventTaskSkipped})
+	}
+}
+
+// report builds the report of the tasks in g. Tasks that never ran are skipped.
+func (x *execution) report(g *Graph, start, end time.Time) *RunReport {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	report := &RunReport{RunID: x.id, Start: start, End: end, Duration: end.Sub(start)}
+	for _, n := range g.Nodes() {
+		st, ok := x.states[n]
+		if !ok {
+			report.Tasks = append(report.Tasks, TaskRecord{Name: nodeName(n), Status: StatusSkipped})
+			continue
+		}
+		report.Tasks = append(report.Tasks, st.record)
+	}
+	return report
+}
@@ -0,0 +1,120 @@
+package taskflow
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// statusColors are the fill and stroke colors of tasks per outcome.
+var statusColors = map[TaskStatus][2]string{
+	StatusSucceeded: {"#d4edda", "#28a745"},
+	StatusFailed:    {"#f8d7da", "#dc3545"},
+	StatusCancelled: {"#fff3cd", "#fd7e14"},
+	StatusSkipped:   {"#e2e3e5", "#6c757d"},
+}
+
+// exportNode is a task of the graph as exported.
+type exportNode struct {
+	id     string
+	label  []string // Lines of the label
+	status TaskStatus
+}
+
+// exportNodes returns the tasks of the graph in topological order, labeled
+// with their name and, if report is set, their outcome in it.
+func (g *Graph) exportNodes(report *RunReport) ([]exportNode, map[Executable]string) {
+	ids := make(map[Executable]string, len(g.nodes))
+	nodes := make([]exportNode, len(g.nodes))
+	for i, e := range g.nodes {
+		n := exportNode{id: fmt.Sprintf("n%d", i), label: []string{nodeName(e)}}
+		if report != nil {
+			if rec, ok := report.Task(nodeName(e)); ok {
+				n.status = rec.Status
+				n.label = append(n.label, outcome(rec))
+			}
+		}
+		ids[e] = n.id
+		nodes[i] = n
+	}
+	return nodes, ids
+}
+
+// outcome describes the status and duration of a task.
+func outcome(rec TaskRecord) string {
+	switch {
+	case rec.Restored:
+		return "restored"
+	case rec.Start.IsZero():
+		return string(rec.Status)
+	}
+	d := rec.Duration.Round(time.Millisecond)
+	if rec.Duration > time.Second {
+		d = rec.Duration.Round(10 * time.Millisecond)
+	}
+	return fmt.Sprintf("%s in %v", rec.Status, d)
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language, with an edge from
+// each dependency to the tasks depending on it. When report is set, tasks are
+// labeled and colored with their outcome in that run; tasks missing from it
+// are left plain.
+func (g *Graph) WriteDOT(w io.Writer, report *RunReport) error {
+	nodes, ids := g.exportNodes(report)
+	b := bufio.NewWriter(w)
+
+	fmt.Fprintln(b, "digraph taskflow {")
+	fmt.Fprintln(b, "  rankdir=LR;")
+	fmt.Fprintln(b, `  node [shape=box, style="rounded,filled", fillcolor="#ffffff"];`)
+	for _, n := range nodes {
+		label := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(strings.Join(n.label, "\n"))
+		label = strings.ReplaceAll(label, "\n", `\n`)
+		if colors, ok := statusColors[n.status]; ok {
+			fmt.Fprintf(b, "  %s [label=\"%s\", fillcolor=%q, color=%q];\n", n.id, label, colors[0], colors[1])
+		} else {
+			fmt.Fprintf(b, "  %s [label=\"%s\"];\n", n.id, label)
+		}
+	}
+	for _, e := range g.nodes {
+		for _, dep := range g.deps[e] {
+			fmt.Fprintf(b, "  %s -> %s;\n", ids[dep], ids[e])
+		}
+	}
+	fmt.Fprintln(b, "}")
+	return b.Flush()
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart, with an edge from each
+// dependency to the tasks depending on it. When report is set, tasks are
+// labeled and colored with their outcome in that run; tasks missing from it
+// are left plain.
+func (g *Graph) WriteMermaid(w io.Writer, report *RunReport) error {
+	nodes, ids := g.exportNodes(report)
+	b := bufio.NewWriter(w)
+
+	fmt.Fprintln(b, "flowchart LR")
+	used := make(map[TaskStatus]bool)
+	for _, n := range nodes {
+		label := strings.ReplaceAll(strings.Join(n.label, "<br/>"), `"`, "#quot;")
+		fmt.Fprintf(b, "    %s[\"%s\"]", n.id, label)
+		if _, ok := statusColors[n.status]; ok {
+			fmt.Fprintf(b, ":::%s", n.status)
+			used[n.status] = true
+		}
+		fmt.Fprintln(b)
+	}
+	for _, e := range g.nodes {
+		for _, dep := range g.deps[e] {
+			fmt.Fprintf(b, "    %s --> %s\n", ids[dep], ids[e])
+		}
+	}
+	for _, status := range []TaskStatus{StatusSucceeded, StatusFailed, StatusCancelled, StatusSkipped} {
+		if used[status] {
+			colors := statusColors[status]
+			fmt.Fprintf(b, "    classDef %s fill:%s,stroke:%s\n", status, colors[0], colors[1])
+		}
+	}
+	return b.Flush()
+}
@@ -0,0 +1,113 @@
+package taskflow_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/josuedeavila/taskflow"
+)
+
+// newExportRunner returns a runner where "load" depends on "fetch" and
+// "check", and check fails.
+func newExportRunner() *taskflow.Runner {
+	fetch := taskflow.NewTask("fetch", func(ctx context.Context, _ any) (any, error) {
+		return nil, nil
+	}).WithLogger(taskflow.NoOpLogger{})
+	check := taskflow.NewTask(`check "input"`, func(ctx context.Context, _ any) (any, error) {
+		return nil, errors.New("invalid")
+	}).WithLogger(taskflow.NoOpLogger{})
+	load := taskflow.NewTask("load", func(ctx context.Context, _ any) (any, error) {
+		return nil, nil
+	}).WithLogger(taskflow.NoOpLogger{}).After(fetch, check)
+
+	runner := taskflow.NewRunner().WithErrorMode(taskflow.ContinueOnError)
+	runner.Add(load)
+	return runner
+}
+
+func TestGraphWriteDOT(t *testing.T) {
+	runner := newExportRunner()
+	g, err := runner.Graph()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var b strings.Builder
+	if err := g.WriteDOT(&b, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `digraph taskflow {
+  rankdir=LR;
+  node [shape=box, style="rounded,filled", fillcolor="#ffffff"];
+  n0 [label="fetch"];
+  n1 [label="check \"input\""];
+  n2 [label="load"];
+  n0 -> n2;
+  n1 -> n2;
+}
+`
+	if b.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+
+	report, _ := runner.Run(context.Background())
+	b.Reset()
+	if err := g.WriteDOT(&b, report); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, expected := range []string{
+		`n0 \[label="fetch\\nsucceeded in \S+", fillcolor="#d4edda", color="#28a745"\];`,
+		`n1 \[label="check \\"input\\"\\nfailed in \S+", fillcolor="#f8d7da", color="#dc3545"\];`,
+		`n2 \[label="load\\nskipped", fillcolor="#e2e3e5", color="#6c757d"\];`,
+	} {
+		if !regexp.MustCompile(expected).MatchString(b.String()) {
+			t.Errorf("Expected output matching %q, got:\n%s", expected, b.String())
+		}
+	}
+}
+
+func TestGraphWriteMermaid(t *testing.T) {
+	runner := newExportRunner()
+	g, err := runner.Graph()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var b strings.Builder
+	if err := g.WriteMermaid(&b, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `flowchart LR
+    n0["fetch"]
+    n1["check #quot;input#quot;"]
+    n2["load"]
+    n0 --> n2
+    n1 --> n2
+`
+	if b.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+
+	report, _ := runner.Run(context.Background())
+	b.Reset()
+	if err := g.WriteMermaid(&b, report); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, expected := range []string{
+		`n0\["fetch<br/>succeeded in \S+"\]:::succeeded`,
+		`n1\["check #quot;input#quot;<br/>failed in \S+"\]:::failed`,
+		`n2\["load<br/>skipped"\]:::skipped`,
+		`classDef succeeded fill:#d4edda,stroke:#28a745`,
+		`classDef skipped fill:#e2e3e5,stroke:#6c757d`,
+	} {
+		if !regexp.MustCompile(expected).MatchString(b.String()) {
+			t.Errorf("Expected output matching %q, got:\n%s", expected, b.String())
+		}
+	}
+	if strings.Contains(b.String(), "classDef cancelled") {
+		t.Errorf("Expected only the classes in use, got:\n%s", b.String())
+	}
+}
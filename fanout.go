@@ -2,50 +2,90 @@ package taskflow
 
 import (
 	"context"
+	"fmt"
+	"iter"
 	"sync"
+	"time"
 )
 
+// Result is the outcome of a single function of a FanOutTask.
+type Result[Out any] struct {
+	Index int // Position of the function in generation or input order
+	Value Out
+	Err   error
+}
+
 // FanOutTask is a task that generates multiple TaskFunc instances,
+// executes them concurrently and combines their results.
+// Instead of generating functions, a FanOutTask can map a single function
+// over its input by setting Map.
 type FanOutTask[In any, Out any] struct {
 	Generate func(ctx context.Context, input []In) ([]TaskFunc[In, Out], error)
+	Map      TaskFunc[In, Out]    // Function called once per input element, used instead of Generate when set
 	FanIn    TaskFunc[[]Out, Out] // Function to combine results from multiple TaskFunc instances
 	Name     string
+
+	// FanInResults combines the results and errors of all functions, used instead
+	// of FanIn when set, so that individual failures don't fail the whole task.
+	FanInResults TaskFunc[[]Result[Out], Out]
+	// MinSuccessRatio is the fraction of functions, between 0 and 1, that must
+	// succeed for FanInResults to be called. Zero tolerates any number of failures.
+	MinSuccessRatio float64
+
+	// FanInStream consumes the results as the functions complete, used instead of
+	// FanIn when set, so they can be aggregated without holding them all in memory.
+	// The results can be iterated once. A function blocks until its result is
+	// consumed, and stopping the iteration early cancels the remaining functions.
+	FanInStream func(ctx context.Context, results iter.Seq2[Out, error]) (Out, error)
+
+	MaxConcurrency int  // Maximum number of generated functions running at once, unlimited if zero
+	CancelOnError  bool // Cancel the remaining functions as soon as one of them fails
+	Repanic        bool // Let panics crash the program instead of returning a PanicError, see Task.WithRepanic
 }
 
 // ToTask converts the FanOutTask into a Task.
-// It generates multiple TaskFunc instances and executes them concurrently.
-// After all functions are executed, it combines their results using the FanIn function.
-// If any function returns an error, it stops execution and returns the first error encountered.
+// It generates multiple TaskFunc instances and executes them concurrently, at most
+// MaxConcurrency at a time. When Map is set, it is called once per element of the
+// task's input, receiving that element, instead. After all functions are executed,
+// it combines their results, in generation or input order, using the FanIn function.
+// If any function returns an error, it returns the first error encountered. With
+// CancelOnError, that error also cancels the context of the functions still running
+// and functions that haven't started yet are not called.
+// When FanInResults is set, failures are passed to it along with the successful
+// results instead, see MinSuccessRatio. When FanInStream is set, results and
+// failures are streamed to it in completion order.
+// A panicking function fails with a *PanicError, like the task itself.
 func (f *FanOutTask[In, Out]) ToTask() *Task[[]In, Out] {
-	return NewTask(f.Name, func(ctx context.Context, input []In) (Out, error) {
+	var task *Task[[]In, Out]
+	task = NewTask(f.Name, func(ctx context.Context, input []In) (Out, error) {
 		var zeroOut Out
-		var zeroIn In 
-		fns, err := f.Generate(ctx, input)
+		n, call, err := f.items(ctx, input)
 		if err != nil {
 			return zeroOut, err
 		}
+		call = observeItems(ctx, task, n, call)
 
-		results := make([]Out, len(fns))
-		var wg sync.WaitGroup
+		if f.FanInStream != nil {
+			return f.fanInStream(ctx, n, call)
+		}
+		if f.FanInResults != nil {
+			return f.fanInResults(ctx, n, call)
+		}
+
+		results := make([]Out, n)
 		var mu sync.Mutex
 		var firstErr error
 
-		for i, fn := range fns {
-			i := i
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				res, err := fn(ctx, zeroIn)
-				mu.Lock()
-				defer mu.Unlock()
-				if err != nil && firstErr == nil {
-					firstErr = err
-				}
-				results[i] = res
-			}()
-		}
-
-		wg.Wait()
+		fanOut(ctx, n, f.MaxConcurrency, f.CancelOnError, func(ctx context.Context, i int) error {
+			res, err := call(ctx, i)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			results[i] = res
+			return err
+		})
 
 		if firstErr != nil {
 			return zeroOut, firstErr
@@ -53,4 +93,184 @@ func (f *FanOutTask[In, Out]) ToTask() *Task[[]In, Out] {
 
 		return f.FanIn(ctx, results)
 	})
+	task.Repanic = f.Repanic
+	return task
+}
+
+// fanInResults runs the n functions and passes all of their outcomes to
+// FanInResults, provided enough of them succeeded.
+func (f *FanOutTask[In, Out]) fanInResults(ctx context.Context, n int, call func(ctx context.Context, i int) (Out, error)) (Out, error) {
+	var zeroOut Out
+	results := make([]Result[Out], n)
+	for i := range results {
+		results[i] = Result[Out]{Index: i, Err: fmt.Errorf("fanout %s: item %d not started: %w", f.Name, i, context.Canceled)}
+	}
+
+	fanOut(ctx, n, f.MaxConcurrency, f.CancelOnError, func(ctx context.Context, i int) error {
+		res, err := call(ctx, i)
+		results[i] = Result[Out]{Index: i, Value: res, Err: err}
+		return err
+	})
+
+	fanOutErr := &FanOutError{Task: f.Name, Total: n, MinSuccessRatio: f.MinSuccessRatio}
+	for _, r := range results {
+		if r.Err != nil {
+			fanOutErr.Failed = append(fanOutErr.Failed, r.Index)
+			fanOutErr.Errors = append(fanOutErr.Errors, r.Err)
+		}
+	}
+	if n > 0 && float64(n-len(fanOutErr.Failed))/float64(n) < f.MinSuccessRatio {
+		return zeroOut, fanOutErr
+	}
+
+	return f.FanInResults(ctx, results)
+}
+
+// fanInStream runs the n functions and streams their outcomes to FanInStream.
+func (f *FanOutTask[In, Out]) fanInStream(ctx context.Context, n int, call func(ctx context.Context, i int) (Out, error)) (Out, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type outcome struct {
+		value Out
+		err   error
+	}
+	outcomes := make(chan outcome)
+
+	go func() {
+		defer close(outcomes)
+		fanOut(ctx, n, f.MaxConcurrency, f.CancelOnError, func(fnCtx context.Context, i int) error {
+			res, err := call(fnCtx, i)
+			select {
+			case outcomes <- outcome{value: res, err: err}:
+			case <-ctx.Done(): // The fan-in stopped consuming
+			}
+			return err
+		})
+	}()
+
+	results := func(yield func(Out, error) bool) {
+		for o := range outcomes {
+			if !yield(o.value, o.err) {
+				cancel()
+				return
+			}
+		}
+	}
+
+	out, err := f.FanInStream(ctx, results)
+
+	// Stop the functions the fan-in didn't wait for and let them return.
+	cancel()
+	for range outcomes {
+	}
+
+	return out, err
+}
+
+// items returns the number of functions to run for input and a function calling the i-th one.
+func (f *FanOutTask[In, Out]) items(ctx context.Context, input []In) (int, func(ctx context.Context, i int) (Out, error), error) {
+	if f.Map != nil {
+		return len(input), func(ctx context.Context, i int) (Out, error) {
+			return safeCall(ctx, f.Name, f.Repanic, f.Map, input[i])
+		}, nil
+	}
+
+	fns, err := f.Generate(ctx, input)
+	if err != nil {
+		return 0, nil, err
+	}
+	return len(fns), func(ctx context.Context, i int) (Out, error) {
+		var zeroIn In
+		return safeCall(ctx, f.Name, f.Repanic, fns[i], zeroIn)
+	}, nil
+}
+
+// observeItems notifies the observers of task that n functions start, and
+// wraps call to notify them of each function call.
+func observeItems[Out any](ctx context.Context, task Executable, n int, call func(ctx context.Context, i int) (Out, error)) func(ctx context.Context, i int) (Out, error) {
+	x := executionFrom(ctx)
+	if x == nil {
+		return call
+	}
+
+	x.emit(ctx, task, Event{Type: EventFanOutStarted, Width: n})
+	return func(ctx context.Context, i int) (Out, error) {
+		start := time.Now()
+		ctx = x.start(ctx, task, Event{Type: EventFanOutItemStarted, Time: start, Index: i})
+		res, err := call(ctx, i)
+		end := time.Now()
+		x.emit(ctx, task, Event{
+			Type:     EventFanOutItemFinished,
+			Time:     end,
+			Start:    start,
+			Duration: end.Sub(start),
+			Index:    i,
+			Err:      err,
+		})
+		return res, err
+	}
+}
+
+// fanOut calls fn for every index in [0, n), running at most limit calls at once,
+// or all of them if limit is zero. With cancelOnError, the first error cancels the
+// context of the calls still running and the calls not yet started are skipped.
+// It returns once every started call has returned.
+func fanOut(ctx context.Context, n, limit int, cancelOnError bool, fn func(ctx context.Context, i int) error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	if limit <= 0 || limit > n {
+		limit = n
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+
+	for range limit {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if cancelOnError && ctx.Err() != nil {
+					continue
+				}
+				if err := fn(ctx, i); err != nil && cancelOnError {
+					cancel(err)
+				}
+			}
+		}()
+	}
+
+	for i := range n {
+		if cancelOnError && ctx.Err() != nil {
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+
+	wg.Wait()
+}
+
+// FanOutError reports that too few functions of a FanOutTask succeeded.
+// It wraps the error of every failed function.
+type FanOutError struct {
+	Task            string
+	Total           int     // Number of functions run
+	MinSuccessRatio float64 // Required fraction of successful functions
+	Failed          []int   // Indexes of the failed functions
+	Errors          []error // Errors of the failed functions, matching Failed
+}
+
+func (e *FanOutError) Error() string {
+	msg := fmt.Sprintf("fanout %s: %d of %d items failed, below the required success ratio %.2f", e.Task, len(e.Failed), e.Total, e.MinSuccessRatio)
+	if len(e.Errors) > 0 {
+		msg += fmt.Sprintf(": item %d: %v", e.Failed[0], e.Errors[0])
+	}
+	return msg
+}
+
+func (e *FanOutError) Unwrap() []error {
+	return e.Errors
 }
@@ -3,7 +3,11 @@ package taskflow_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"iter"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -304,3 +308,375 @@ func TestFanOutTask_ToTask_TypeSafety(t *testing.T) {
 		t.Error("Expected non-empty result")
 	}
 }
+
+func TestFanOutTask_ToTask_MaxConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	current, peak := 0, 0
+
+	fanOut := &taskflow.FanOutTask[any, int]{
+		Name:           "test_fanout_max_concurrency",
+		MaxConcurrency: 3,
+		Generate: func(ctx context.Context, _ []any) ([]taskflow.TaskFunc[any, int], error) {
+			fns := make([]taskflow.TaskFunc[any, int], 10)
+			for i := range fns {
+				fns[i] = func(ctx context.Context, _ any) (int, error) {
+					mu.Lock()
+					current++
+					peak = max(peak, current)
+					mu.Unlock()
+
+					time.Sleep(10 * time.Millisecond)
+
+					mu.Lock()
+					current--
+					mu.Unlock()
+					return i, nil
+				}
+			}
+			return fns, nil
+		},
+		FanIn: func(ctx context.Context, results []int) (int, error) {
+			sum := 0
+			for _, r := range results {
+				sum += r
+			}
+			return sum, nil
+		},
+	}
+
+	result, err := fanOut.ToTask().Run(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result != 45 {
+		t.Errorf("Expected result 45, got %v", result)
+	}
+	if peak != 3 {
+		t.Errorf("Expected at most 3 functions at once, got %d", peak)
+	}
+}
+
+func TestFanOutTask_ToTask_CancelOnError(t *testing.T) {
+	expectedErr := errors.New("task func failed")
+	var mu sync.Mutex
+	started := 0
+	cancelled := false
+
+	fanOut := &taskflow.FanOutTask[any, string]{
+		Name:           "test_fanout_cancel_on_error",
+		MaxConcurrency: 2,
+		CancelOnError:  true,
+		Generate: func(ctx context.Context, _ []any) ([]taskflow.TaskFunc[any, string], error) {
+			fns := []taskflow.TaskFunc[any, string]{
+				func(ctx context.Context, _ any) (string, error) {
+					return "", expectedErr
+				},
+			}
+			for i := 0; i < 5; i++ {
+				fns = append(fns, func(ctx context.Context, _ any) (string, error) {
+					mu.Lock()
+					started++
+					mu.Unlock()
+					select {
+					case <-time.After(time.Second):
+						return "slow", nil
+					case <-ctx.Done():
+						mu.Lock()
+						cancelled = true
+						mu.Unlock()
+						return "", ctx.Err()
+					}
+				})
+			}
+			return fns, nil
+		},
+		FanIn: func(ctx context.Context, results []string) (string, error) {
+			return "should not reach here", nil
+		},
+	}
+
+	start := time.Now()
+	_, err := fanOut.ToTask().Run(context.Background(), nil)
+	duration := time.Since(start)
+
+	if err != expectedErr {
+		t.Errorf("Expected error %v, got %v", expectedErr, err)
+	}
+	if duration > 500*time.Millisecond {
+		t.Errorf("Expected remaining functions to be cancelled, took %v", duration)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if started > 2 {
+		t.Errorf("Expected pending functions not to start after the failure, %d started", started)
+	}
+	if started > 0 && !cancelled {
+		t.Error("Expected running functions to see the cancellation")
+	}
+}
+
+func TestFanOutTask_ToTask_Map(t *testing.T) {
+	var mu sync.Mutex
+	var received []int
+
+	fanOut := &taskflow.FanOutTask[int, string]{
+		Name: "test_fanout_map",
+		Map: func(ctx context.Context, input int) (string, error) {
+			mu.Lock()
+			received = append(received, input)
+			mu.Unlock()
+			// Later elements finish first, results must still follow input order
+			time.Sleep(time.Duration(5-input) * 5 * time.Millisecond)
+			return fmt.Sprintf("item%d", input), nil
+		},
+		FanIn: func(ctx context.Context, results []string) (string, error) {
+			return strings.Join(results, ","), nil
+		},
+	}
+
+	result, err := fanOut.ToTask().Run(context.Background(), []int{1, 2, 3, 4})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := "item1,item2,item3,item4"
+	if result != expected {
+		t.Errorf("Expected result %q, got %v", expected, result)
+	}
+	if len(received) != 4 {
+		t.Errorf("Expected Map to be called once per element, got %v", received)
+	}
+}
+
+func TestFanOutTask_ToTask_MapFromDependency(t *testing.T) {
+	urls := taskflow.NewTask("urls", func(ctx context.Context, _ any) ([]string, error) {
+		return []string{"a", "bb", "ccc"}, nil
+	})
+
+	fanOut := &taskflow.FanOutTask[string, int]{
+		Name: "lengths",
+		Map: func(ctx context.Context, url string) (int, error) {
+			return len(url), nil
+		},
+		FanIn: func(ctx context.Context, results []int) (int, error) {
+			total := 0
+			for _, r := range results {
+				total += r
+			}
+			return total, nil
+		},
+		MaxConcurrency: 2,
+	}
+
+	result, err := fanOut.ToTask().After(urls).Run(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result != 6 {
+		t.Errorf("Expected result 6, got %v", result)
+	}
+}
+
+func TestFanOutTask_ToTask_FanInResults(t *testing.T) {
+	itemErr := errors.New("item failed")
+
+	tests := []struct {
+		name            string
+		failing         map[int]bool
+		minSuccessRatio float64
+		wantErr         bool
+		expected        string
+	}{
+		{
+			name:            "tolerates failures above the threshold",
+			failing:         map[int]bool{2: true},
+			minSuccessRatio: 0.8,
+			expected:        "ok=4 failed=[2]",
+		},
+		{
+			name:            "fails below the threshold",
+			failing:         map[int]bool{1: true, 3: true},
+			minSuccessRatio: 0.8,
+			wantErr:         true,
+		},
+		{
+			name:     "no threshold tolerates every failure",
+			failing:  map[int]bool{0: true, 1: true, 2: true, 3: true, 4: true},
+			expected: "ok=0 failed=[0 1 2 3 4]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fanOut := &taskflow.FanOutTask[int, string]{
+				Name: "test_fanout_partial",
+				Map: func(ctx context.Context, input int) (string, error) {
+					if tt.failing[input] {
+						return "", itemErr
+					}
+					return fmt.Sprint(input), nil
+				},
+				FanInResults: func(ctx context.Context, results []taskflow.Result[string]) (string, error) {
+					ok := 0
+					var failed []int
+					for i, r := range results {
+						if r.Index != i {
+							t.Errorf("Expected result %d to have index %d, got %d", i, i, r.Index)
+						}
+						if r.Err != nil {
+							failed = append(failed, r.Index)
+							continue
+						}
+						ok++
+					}
+					return fmt.Sprintf("ok=%d failed=%v", ok, failed), nil
+				},
+				MinSuccessRatio: tt.minSuccessRatio,
+			}
+
+			result, err := fanOut.ToTask().Run(context.Background(), []int{0, 1, 2, 3, 4})
+
+			if tt.wantErr {
+				var fanOutErr *taskflow.FanOutError
+				if !errors.As(err, &fanOutErr) {
+					t.Fatalf("Expected FanOutError, got %v", err)
+				}
+				if len(fanOutErr.Failed) != len(tt.failing) || fanOutErr.Total != 5 {
+					t.Errorf("Expected %d of 5 items to fail, got %d of %d", len(tt.failing), len(fanOutErr.Failed), fanOutErr.Total)
+				}
+				if !errors.Is(err, itemErr) {
+					t.Errorf("Expected FanOutError to wrap the item errors, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected result %q, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestFanOutTask_ToTask_FanInStream(t *testing.T) {
+	var order []int
+
+	fanOut := &taskflow.FanOutTask[int, int]{
+		Name: "test_fanout_stream",
+		Map: func(ctx context.Context, input int) (int, error) {
+			time.Sleep(time.Duration(input) * 10 * time.Millisecond)
+			if input == 2 {
+				return 0, errors.New("item failed")
+			}
+			return input, nil
+		},
+		FanInStream: func(ctx context.Context, results iter.Seq2[int, error]) (int, error) {
+			sum := 0
+			for value, err := range results {
+				if err != nil {
+					order = append(order, -1)
+					continue
+				}
+				order = append(order, value)
+				sum += value
+			}
+			return sum, nil
+		},
+	}
+
+	result, err := fanOut.ToTask().Run(context.Background(), []int{4, 1, 3, 2})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result != 8 {
+		t.Errorf("Expected running sum 8, got %v", result)
+	}
+
+	// Results arrive in completion order, not input order
+	expected := []int{1, -1, 3, 4}
+	if fmt.Sprint(order) != fmt.Sprint(expected) {
+		t.Errorf("Expected completion order %v, got %v", expected, order)
+	}
+}
+
+func TestFanOutTask_ToTask_FanInStreamBackpressure(t *testing.T) {
+	var started atomic.Int32
+
+	fanOut := &taskflow.FanOutTask[int, int]{
+		Name:           "test_fanout_stream_backpressure",
+		MaxConcurrency: 1,
+		Map: func(ctx context.Context, input int) (int, error) {
+			started.Add(1)
+			return input, nil
+		},
+		FanInStream: func(ctx context.Context, results iter.Seq2[int, error]) (int, error) {
+			consumed := 0
+			for range results {
+				consumed++
+				time.Sleep(5 * time.Millisecond)
+				// The producer can only be one result ahead of the consumer
+				if ahead := int(started.Load()) - consumed; ahead > 2 {
+					t.Errorf("Expected backpressure, producer is %d results ahead", ahead)
+				}
+			}
+			return consumed, nil
+		},
+	}
+
+	result, err := fanOut.ToTask().Run(context.Background(), make([]int, 10))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result != 10 {
+		t.Errorf("Expected 10 results consumed, got %v", result)
+	}
+}
+
+func TestFanOutTask_ToTask_FanInStreamStopEarly(t *testing.T) {
+	var cancelled atomic.Int32
+
+	fanOut := &taskflow.FanOutTask[int, int]{
+		Name: "test_fanout_stream_stop",
+		Map: func(ctx context.Context, input int) (int, error) {
+			if input == 0 {
+				return 0, nil
+			}
+			select {
+			case <-time.After(time.Second):
+				return input, nil
+			case <-ctx.Done():
+				cancelled.Add(1)
+				return 0, ctx.Err()
+			}
+		},
+		FanInStream: func(ctx context.Context, results iter.Seq2[int, error]) (int, error) {
+			for value := range results {
+				return value, nil // Only the first result is needed
+			}
+			return -1, nil
+		},
+	}
+
+	start := time.Now()
+	result, err := fanOut.ToTask().Run(context.Background(), []int{0, 1, 2, 3})
+	duration := time.Since(start)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result != 0 {
+		t.Errorf("Expected first result 0, got %v", result)
+	}
+	if duration > 500*time.Millisecond {
+		t.Errorf("Expected remaining functions to be cancelled, took %v", duration)
+	}
+	if cancelled.Load() != 3 {
+		t.Errorf("Expected 3 functions to be cancelled, got %d", cancelled.Load())
+	}
+}
@@ -0,0 +1,272 @@
+package taskflow
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Graph is a validated view of the tasks reachable from a set of root
+// executables through their dependencies.
+type Graph struct {
+	roots []Executable
+	nodes []Executable // Topological order, dependencies first
+	deps  map[Executable][]Executable
+}
+
+// inputChecker is implemented by tasks that can verify the types flowing in
+// from their dependencies.
+type inputChecker interface {
+	checkInputTypes() []error
+}
+
+// NewGraph walks the dependencies of the given tasks and validates the resulting graph.
+// It reports dependency cycles, nil dependencies, task names used more than once,
+// tasks of sequential chains shared with other tasks and outputs that cannot be
+// delivered to the input of the task depending on them.
+// All problems found are joined into the returned error.
+func NewGraph(tasks ...Executable) (*Graph, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	g := &Graph{deps: make(map[Executable][]Executable)}
+	state := make(map[Executable]int)
+	var stack []Executable
+	var errs []error
+	cyclic := false
+
+	var visit func(e Executable)
+	visit = func(e Executable) {
+		switch state[e] {
+		case visiting:
+			var path []string
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i] == e {
+					for _, n := range stack[i:] {
+						path = append(path, nodeName(n))
+					}
+					break
+				}
+			}
+			errs = append(errs, &CycleError{Path: append(path, nodeName(e))})
+			cyclic = true
+			return
+		case visited:
+			return
+		}
+
+		state[e] = visiting
+		stack = append(stack, e)
+
+		var deps []Executable
+		for i, dep := range dependenciesOf(e) {
+			if isNil(dep) {
+				errs = append(errs, &DanglingDependencyError{Task: nodeName(e), Index: i})
+				continue
+			}
+			deps = append(deps, dep)
+			visit(dep)
+		}
+
+		g.deps[e] = deps
+		stack = stack[:len(stack)-1]
+		state[e] = visited
+		g.nodes = append(g.nodes, e)
+	}
+
+	for i, t := range tasks {
+		if isNil(t) {
+			errs = append(errs, fmt.Errorf("graph: task %d is nil", i))
+			continue
+		}
+		if state[t] == unvisited {
+			g.roots = append(g.roots, t)
+		}
+		visit(t)
+	}
+
+	counts := make(map[string]int)
+	var names []string
+	for _, n := range g.nodes {
+		node, ok := n.(Node)
+		if !ok || node.GetName() == "" {
+			continue
+		}
+		if counts[node.GetName()] == 0 {
+			names = append(names, node.GetName())
+		}
+		counts[node.GetName()]++
+	}
+	for _, name := range names {
+		if counts[name] > 1 {
+			errs = append(errs, &DuplicateNameError{Name: name, Count: counts[name]})
+		}
+	}
+
+	if !cyclic {
+		errs = append(errs, g.checkChains(tasks)...)
+	}
+
+	for _, n := range g.nodes {
+		if c, ok := n.(inputChecker); ok {
+			errs = append(errs, c.checkInputTypes()...)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return g, nil
+}
+
+// checkChains reports the tasks running inside a sequential chain that are also
+// among the given tasks or dependencies of other tasks. Chains run them with the output of the
+// previous link, so they can't be shared with tasks giving them another input.
+func (g *Graph) checkChains(tasks []Executable) []error {
+	dependents := make(map[Executable][]Executable)
+	for _, n := range g.nodes {
+		for _, dep := range g.deps[n] {
+			dependents[dep] = append(dependents[dep], n)
+		}
+	}
+	for _, t := range tasks {
+		if !isNil(t) && !slices.Contains(dependents[t], nil) {
+			dependents[t] = append(dependents[t], nil)
+		}
+	}
+
+	var errs []error
+	reported := make(map[Executable]bool)
+	var visit func(chain, e Executable)
+	visit = func(chain, e Executable) {
+		if len(dependents[e]) > 1 && !reported[e] {
+			reported[e] = true
+			errs = append(errs, &SharedChainError{Task: nodeName(e), Chain: nodeName(chain)})
+		}
+		for _, dep := range g.deps[e] {
+			visit(chain, dep)
+		}
+	}
+	for _, n := range g.nodes {
+		if inlinesDependencies(n) {
+			for _, dep := range g.deps[n] {
+				visit(n, dep)
+			}
+		}
+	}
+	return errs
+}
+
+// Roots returns the tasks the graph was built from, without duplicates.
+func (g *Graph) Roots() []Executable {
+	return g.roots
+}
+
+// Nodes returns every task in the graph in topological order, so each task
+// appears after all of its dependencies.
+func (g *Graph) Nodes() []Executable {
+	return g.nodes
+}
+
+// Dependencies returns the direct dependencies of a task in the graph.
+func (g *Graph) Dependencies(e Executable) []Executable {
+	return g.deps[e]
+}
+
+// CycleError reports a dependency cycle. Path holds the names of the tasks
+// along the cycle, starting and ending with the same task.
+type CycleError struct {
+	Path []string
+}
+
+func (e *CycleError) Error() string {
+	return "graph: dependency cycle: " + strings.Join(e.Path, " -> ")
+}
+
+// DanglingDependencyError reports a nil entry in a task's dependencies.
+type DanglingDependencyError struct {
+	Task  string
+	Index int
+}
+
+func (e *DanglingDependencyError) Error() string {
+	return fmt.Sprintf("graph: task %s: dependency %d is nil", e.Task, e.Index)
+}
+
+// DuplicateNameError reports a task name shared by several tasks.
+type DuplicateNameError struct {
+	Name  string
+	Count int
+}
+
+func (e *DuplicateNameError) Error() string {
+	return fmt.Sprintf("graph: task name %q is used by %d tasks", e.Name, e.Count)
+}
+
+// SharedChainError reports a task running inside the sequential dependencies of
+// Chain that other tasks depend on too, or that is itself a root.
+type SharedChainError struct {
+	Task  string
+	Chain string
+}
+
+func (e *SharedChainError) Error() string {
+	return fmt.Sprintf("graph: task %s runs in the sequential dependencies of %s and cannot be shared with other tasks", e.Task, e.Chain)
+}
+
+// TypeMismatchError reports an output that can never be delivered to the
+// input of the task consuming it.
+type TypeMismatchError struct {
+	Producer string
+	Consumer string
+	Output   reflect.Type
+	Input    reflect.Type
+}
+
+func (e *TypeMismatchError) Error() string {
+	return fmt.Sprintf("graph: task %s cannot receive the output of %s: expected %s, got %s", e.Consumer, e.Producer, e.Input, e.Output)
+}
+
+// dependenciesOf returns the dependencies of e, or nil if it doesn't expose them.
+func dependenciesOf(e Executable) []Executable {
+	if n, ok := e.(Node); ok {
+		return n.GetDependencies()
+	}
+	return nil
+}
+
+// nodeName returns the name of e, falling back to its type for unnamed executables.
+func nodeName(e Executable) string {
+	if n, ok := e.(Node); ok && n.GetName() != "" {
+		return n.GetName()
+	}
+	return fmt.Sprintf("%T", e)
+}
+
+// isNil reports whether e is nil or wraps a nil pointer.
+func isNil(e Executable) bool {
+	if e == nil {
+		return true
+	}
+	v := reflect.ValueOf(e)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// assignable reports whether a value produced with the static type output always
+// passes the input type assertion of a task expecting input. Interface outputs
+// are only known at run time and are accepted.
+func assignable(output, input reflect.Type) bool {
+	if output == input || output.Kind() == reflect.Interface {
+		return true
+	}
+	return input.Kind() == reflect.Interface && output.Implements(input)
+}
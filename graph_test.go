@@ -0,0 +1,210 @@
+package taskflow_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/josuedeavila/taskflow"
+)
+
+func newNamedTask(name string) *taskflow.Task[any, string] {
+	return taskflow.NewTask(name, func(ctx context.Context, input any) (string, error) {
+		return name, nil
+	})
+}
+
+func TestNewGraph_TopologicalOrder(t *testing.T) {
+	a := newNamedTask("a")
+	b := newNamedTask("b").After(a)
+	c := newNamedTask("c").After(a)
+	d := taskflow.NewTask("d", func(ctx context.Context, input []any) (int, error) {
+		return len(input), nil
+	}).After(b, c)
+
+	g, err := taskflow.NewGraph(d)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	nodes := g.Nodes()
+	if len(nodes) != 4 {
+		t.Fatalf("Expected 4 nodes, got %d", len(nodes))
+	}
+
+	position := make(map[taskflow.Executable]int)
+	for i, n := range nodes {
+		position[n] = i
+	}
+	for _, n := range nodes {
+		for _, dep := range g.Dependencies(n) {
+			if position[dep] >= position[n] {
+				t.Errorf("Expected dependency %v before %v", dep, n)
+			}
+		}
+	}
+
+	if len(g.Roots()) != 1 || g.Roots()[0] != d {
+		t.Errorf("Expected d to be the only root, got %v", g.Roots())
+	}
+}
+
+func TestNewGraph_Cycle(t *testing.T) {
+	a := newNamedTask("a")
+	b := newNamedTask("b")
+	c := newNamedTask("c")
+	a.After(c)
+	b.After(a)
+	c.After(b)
+
+	_, err := taskflow.NewGraph(a)
+
+	var cycleErr *taskflow.CycleError
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf("Expected CycleError, got %v", err)
+	}
+
+	path := strings.Join(cycleErr.Path, " -> ")
+	if path != "a -> c -> b -> a" {
+		t.Errorf("Expected cycle path 'a -> c -> b -> a', got '%s'", path)
+	}
+}
+
+func TestNewGraph_DanglingDependency(t *testing.T) {
+	var missing *taskflow.Task[any, string]
+	a := newNamedTask("a").After(nil, missing)
+
+	_, err := taskflow.NewGraph(a)
+
+	var danglingErr *taskflow.DanglingDependencyError
+	if !errors.As(err, &danglingErr) {
+		t.Fatalf("Expected DanglingDependencyError, got %v", err)
+	}
+	if danglingErr.Task != "a" {
+		t.Errorf("Expected dangling dependency on task 'a', got '%s'", danglingErr.Task)
+	}
+	if strings.Count(err.Error(), "is nil") != 2 {
+		t.Errorf("Expected both nil dependencies to be reported, got %v", err)
+	}
+}
+
+func TestNewGraph_DuplicateNames(t *testing.T) {
+	_, err := taskflow.NewGraph(newNamedTask("fetch"), newNamedTask("fetch"))
+
+	var dupErr *taskflow.DuplicateNameError
+	if !errors.As(err, &dupErr) {
+		t.Fatalf("Expected DuplicateNameError, got %v", err)
+	}
+	if dupErr.Name != "fetch" || dupErr.Count != 2 {
+		t.Errorf("Expected name 'fetch' used 2 times, got %q used %d times", dupErr.Name, dupErr.Count)
+	}
+}
+
+func TestNewGraph_SharedChain(t *testing.T) {
+	a := taskflow.NewTask("a", func(ctx context.Context, input any) (int, error) {
+		return 21, nil
+	})
+	b := taskflow.NewTask("b", func(ctx context.Context, input int) (int, error) {
+		return input * 2, nil
+	})
+	seq := taskflow.NewTask("seq", func(ctx context.Context, input int) (int, error) {
+		return input, nil
+	}).WithDependencyMode(taskflow.SequentialDependencies).After(a, b)
+	other := taskflow.NewTask("other", func(ctx context.Context, input int) (int, error) {
+		return input, nil
+	}).After(b)
+
+	runner := taskflow.NewRunner()
+	runner.Add(seq, other)
+	_, err := runner.Run(context.Background())
+
+	var sharedErr *taskflow.SharedChainError
+	if !errors.As(err, &sharedErr) {
+		t.Fatalf("Expected SharedChainError, got %v", err)
+	}
+	if sharedErr.Task != "b" || sharedErr.Chain != "seq" {
+		t.Errorf("Expected task 'b' shared from chain 'seq', got %q from %q", sharedErr.Task, sharedErr.Chain)
+	}
+
+	runner = taskflow.NewRunner()
+	runner.Add(seq)
+	if _, err := runner.Run(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if seq.Result != 42 {
+		t.Errorf("Expected result 42, got %d", seq.Result)
+	}
+
+	if _, err := taskflow.NewGraph(seq, b); !errors.As(err, &sharedErr) {
+		t.Errorf("Expected SharedChainError for a chain member added as a root, got %v", err)
+	}
+}
+
+func TestNewGraph_TypeMismatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		task    taskflow.Executable
+		wantErr bool
+	}{
+		{
+			name: "matching types",
+			task: taskflow.NewTask("consumer", func(ctx context.Context, input string) (int, error) {
+				return len(input), nil
+			}).After(newNamedTask("producer")),
+			wantErr: false,
+		},
+		{
+			name: "interface input",
+			task: taskflow.NewTask("consumer", func(ctx context.Context, input any) (int, error) {
+				return 0, nil
+			}).After(newNamedTask("producer")),
+			wantErr: false,
+		},
+		{
+			name: "mismatched types",
+			task: taskflow.NewTask("consumer", func(ctx context.Context, input int) (int, error) {
+				return input, nil
+			}).After(newNamedTask("producer")),
+			wantErr: true,
+		},
+		{
+			name: "several dependencies into a non-slice input",
+			task: taskflow.NewTask("consumer", func(ctx context.Context, input string) (int, error) {
+				return 0, nil
+			}).After(newNamedTask("p1"), newNamedTask("p2")),
+			wantErr: true,
+		},
+		{
+			name: "sequential chain",
+			task: taskflow.NewTask("consumer", func(ctx context.Context, input string) (int, error) {
+				return 0, nil
+			}).WithDependencyMode(taskflow.SequentialDependencies).After(
+				newNamedTask("p1"),
+				taskflow.NewTask("p2", func(ctx context.Context, input int) (string, error) { return "", nil }),
+			),
+			wantErr: true,
+		},
+		{
+			name: "join inputs",
+			task: taskflow.Join2("consumer", newNamedTask("p1"), newNamedTask("p2"), func(ctx context.Context, a string, b int) (int, error) {
+				return b, nil
+			}),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := taskflow.NewGraph(tt.task)
+
+			var typeErr *taskflow.TypeMismatchError
+			if tt.wantErr && !errors.As(err, &typeErr) {
+				t.Errorf("Expected TypeMismatchError, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package taskflow
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Input returns the i-th dependency output from the []any a task with several
+// dependencies receives, converted to T. A nil output yields T's zero value.
+func Input[T any](inputs []any, i int) (T, error) {
+	var zero T
+	if i < 0 || i >= len(inputs) {
+		return zero, fmt.Errorf("task: input %d out of range: task has %d inputs", i, len(inputs))
+	}
+	if inputs[i] == nil {
+		return zero, nil
+	}
+	typed, ok := inputs[i].(T)
+	if !ok {
+		return zero, fmt.Errorf("task: input %d type mismatch: expected %T, got %T", i, zero, inputs[i])
+	}
+	return typed, nil
+}
+
+// Join2 creates a task that runs a and b concurrently and calls fn with both
+// results, typed as A and B.
+func Join2[A, B, Out any](name string, a, b Executable, fn func(ctx context.Context, a A, b B) (Out, error)) *Task[[]any, Out] {
+	task := NewTask(name, func(ctx context.Context, inputs []any) (Out, error) {
+		var zeroOut Out
+		va, err := Input[A](inputs, 0)
+		if err != nil {
+			return zeroOut, err
+		}
+		vb, err := Input[B](inputs, 1)
+		if err != nil {
+			return zeroOut, err
+		}
+		return fn(ctx, va, vb)
+	}).After(a, b)
+	task.inputTypes = []reflect.Type{reflect.TypeFor[A](), reflect.TypeFor[B]()}
+	return task
+}
+
+// Join3 creates a task that runs a, b and c concurrently and calls fn with
+// all three results, typed as A, B and C.
+func Join3[A, B, C, Out any](name string, a, b, c Executable, fn func(ctx context.Context, a A, b B, c C) (Out, error)) *Task[[]any, Out] {
+	task := NewTask(name, func(ctx context.Context, inputs []any) (Out, error) {
+		var zeroOut Out
+		va, err := Input[A](inputs, 0)
+		if err != nil {
+			return zeroOut, err
+		}
+		vb, err := Input[B](inputs, 1)
+		if err != nil {
+			return zeroOut, err
+		}
+		vc, err := Input[C](inputs, 2)
+		if err != nil {
+			return zeroOut, err
+		}
+		return fn(ctx, va, vb, vc)
+	}).After(a, b, c)
+	task.inputTypes = []reflect.Type{reflect.TypeFor[A](), reflect.TypeFor[B](), reflect.TypeFor[C]()}
+	return task
+}
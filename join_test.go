@@ -0,0 +1,97 @@
+package taskflow_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/josuedeavila/taskflow"
+)
+
+func TestJoin2(t *testing.T) {
+	users := taskflow.NewTask("users", func(ctx context.Context, input any) ([]string, error) {
+		return []string{"ana", "bruno"}, nil
+	})
+	products := taskflow.NewTask("products", func(ctx context.Context, input any) (int, error) {
+		return 3, nil
+	})
+
+	report := taskflow.Join2("report", users, products, func(ctx context.Context, u []string, p int) (string, error) {
+		return fmt.Sprintf("%d users, %d products", len(u), p), nil
+	})
+
+	result, err := report.Run(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "2 users, 3 products"
+	if result != expected {
+		t.Errorf("Expected result %q, got %v", expected, result)
+	}
+}
+
+func TestJoin3(t *testing.T) {
+	a := taskflow.NewTask("a", func(ctx context.Context, input any) (string, error) { return "a", nil })
+	b := taskflow.NewTask("b", func(ctx context.Context, input any) (int, error) { return 2, nil })
+	c := taskflow.NewTask("c", func(ctx context.Context, input any) (bool, error) { return true, nil })
+
+	joined := taskflow.Join3("joined", a, b, c, func(ctx context.Context, a string, b int, c bool) (string, error) {
+		return fmt.Sprintf("%s-%d-%t", a, b, c), nil
+	})
+
+	result, err := joined.Run(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result != "a-2-true" {
+		t.Errorf("Expected result 'a-2-true', got %v", result)
+	}
+}
+
+func TestJoin2_TypeMismatch(t *testing.T) {
+	a := taskflow.NewTask("a", func(ctx context.Context, input any) (string, error) { return "a", nil })
+	b := taskflow.NewTask("b", func(ctx context.Context, input any) (string, error) { return "b", nil })
+
+	joined := taskflow.Join2("joined", a, b, func(ctx context.Context, a string, b int) (string, error) {
+		return "should not reach here", nil
+	})
+
+	_, err := joined.Run(context.Background(), nil)
+	if err == nil {
+		t.Fatal("Expected type mismatch error, got nil")
+	}
+
+	if !strings.Contains(err.Error(), "input 1") {
+		t.Errorf("Expected error to name input 1, got %v", err)
+	}
+}
+
+func TestInput(t *testing.T) {
+	inputs := []any{"hello", 42, nil}
+
+	s, err := taskflow.Input[string](inputs, 0)
+	if err != nil || s != "hello" {
+		t.Errorf("Expected 'hello', got %q (err: %v)", s, err)
+	}
+
+	n, err := taskflow.Input[int](inputs, 1)
+	if err != nil || n != 42 {
+		t.Errorf("Expected 42, got %d (err: %v)", n, err)
+	}
+
+	zero, err := taskflow.Input[[]string](inputs, 2)
+	if err != nil || zero != nil {
+		t.Errorf("Expected nil slice for nil input, got %v (err: %v)", zero, err)
+	}
+
+	if _, err := taskflow.Input[int](inputs, 0); err == nil {
+		t.Error("Expected type mismatch error, got nil")
+	}
+
+	if _, err := taskflow.Input[int](inputs, 5); err == nil {
+		t.Error("Expected out of range error, got nil")
+	}
+}
@@ -1,16 +1,52 @@
 package taskflow
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"log/slog"
 	"os"
+	"strconv"
+	"strings"
 )
 
 // A Logger is a minimalistic interface for the taskflow to log messages to. Should
 // be used to provide custom logging writers for the taskflow to use.
+//
+// Loggers that also implement LeveledLogger receive every message with its level
+// and fields. Other loggers receive messages at LevelInfo and above, formatted
+// as a single line of text.
 type Logger interface {
 	Log(args ...any)
 }
 
+// Level is the importance of a log message. It is the same type as slog.Level.
+type Level = slog.Level
+
+// Log levels, the same as slog's.
+const (
+	LevelDebug = slog.LevelDebug
+	LevelInfo  = slog.LevelInfo
+	LevelWarn  = slog.LevelWarn
+	LevelError = slog.LevelError
+)
+
+// Field is a key-value pair attached to a log message. It is the same type as slog.Attr.
+type Field = slog.Attr
+
+// Log field keys added by tasks to each of their messages.
+const (
+	FieldTask    = "task"    // Name of the task
+	FieldRunID   = "run_id"  // ID of the execution, see RunReport.RunID
+	FieldAttempt = "attempt" // Number of the call to the task function, starting at 1
+)
+
+// LeveledLogger is a Logger that accepts structured messages with a level and fields.
+type LeveledLogger interface {
+	Logger
+	LogLevel(ctx context.Context, level Level, msg string, fields ...Field)
+}
+
 // A LoggerFunc is a convenience type to convert a function taking a variadic
 // list of arguments and wrap it so the Logger interface can be used.
 type LoggerFunc func(...any)
@@ -20,6 +56,29 @@ func (f LoggerFunc) Log(args ...any) {
 	f(args...)
 }
 
+// SlogLogger is a LeveledLogger writing to a slog.Logger.
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger returns a Logger writing to l, or to slog.Default() if l is nil.
+func NewSlogLogger(l *slog.Logger) *SlogLogger {
+	if l == nil {
+		l = slog.Default()
+	}
+	return &SlogLogger{logger: l}
+}
+
+// Log logs the arguments at LevelInfo. See fmt.Sprint.
+func (l *SlogLogger) Log(args ...any) {
+	l.logger.Info(fmt.Sprint(args...))
+}
+
+// LogLevel logs msg with its fields if the slog.Logger is enabled for level.
+func (l *SlogLogger) LogLevel(ctx context.Context, level Level, msg string, fields ...Field) {
+	l.logger.LogAttrs(ctx, level, msg, fields...)
+}
+
 // newDefaultLogger returns a Logger which will write log messages to stdout
 //
 //	and use the same formatting runes as the stdlib log.Logger
@@ -39,8 +98,49 @@ func (l defaultLogger) Log(args ...any) {
 	l.logger.Println(args...)
 }
 
+// LogLevel logs messages at LevelInfo and above as a line of text.
+func (l defaultLogger) LogLevel(ctx context.Context, level Level, msg string, fields ...Field) {
+	if level >= LevelInfo {
+		l.logger.Println(formatMessage(level, msg, fields))
+	}
+}
+
 // NoOpLogger is a logger that does nothing.
 type NoOpLogger struct{}
 
 // Log implements Logger but does nothing.
 func (NoOpLogger) Log(args ...any) {}
+
+// LogLevel implements LeveledLogger but does nothing.
+func (NoOpLogger) LogLevel(ctx context.Context, level Level, msg string, fields ...Field) {}
+
+// logMessage sends a message to logger, formatting it as text for loggers that
+// don't implement LeveledLogger. A nil logger discards the message.
+func logMessage(ctx context.Context, logger Logger, level Level, msg string, fields ...Field) {
+	switch l := logger.(type) {
+	case nil:
+	case LeveledLogger:
+		l.LogLevel(ctx, level, msg, fields...)
+	default:
+		if level >= LevelInfo {
+			l.Log(formatMessage(level, msg, fields))
+		}
+	}
+}
+
+// formatMessage renders a message as "LEVEL msg key=value ...", quoting values
+// that contain spaces.
+func formatMessage(level Level, msg string, fields []Field) string {
+	var b strings.Builder
+	b.WriteString(level.String())
+	b.WriteByte(' ')
+	b.WriteString(msg)
+	for _, f := range fields {
+		value := f.Value.String()
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = strconv.Quote(value)
+		}
+		fmt.Fprintf(&b, " %s=%s", f.Key, value)
+	}
+	return b.String()
+}
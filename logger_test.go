@@ -2,11 +2,16 @@ package taskflow_test
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/josuedeavila/taskflow"
 )
@@ -70,3 +75,121 @@ func TestNoOpLogger_Log(t *testing.T) {
 	logger := taskflow.NoOpLogger{}
 	logger.Log("This should not appear anywhere")
 }
+
+func TestSlogLogger_TaskFields(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+
+	calls := 0
+	task := taskflow.NewTask("flaky", func(ctx context.Context, input any) (string, error) {
+		calls++
+		if calls == 1 {
+			return "", errors.New("temporary failure")
+		}
+		return "ok", nil
+	}).WithRetry(taskflow.RetryPolicy{
+		MaxRetries: 1,
+		Strategy:   taskflow.ConstantBackoff,
+		BaseDelay:  time.Millisecond,
+	}).WithLogger(taskflow.NewSlogLogger(slog.New(handler)))
+
+	runner := taskflow.NewRunner()
+	runner.Add(task)
+
+	report, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Expected JSON log line, got %q", line)
+		}
+		records = append(records, record)
+	}
+
+	// Two attempts started, one retry
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 log records, got %d: %v", len(records), records)
+	}
+
+	for _, record := range records {
+		if record[taskflow.FieldTask] != "flaky" {
+			t.Errorf("Expected task 'flaky', got %v", record[taskflow.FieldTask])
+		}
+		if record[taskflow.FieldRunID] != report.RunID {
+			t.Errorf("Expected run ID %s, got %v", report.RunID, record[taskflow.FieldRunID])
+		}
+	}
+
+	retry := records[1]
+	if retry["level"] != "WARN" {
+		t.Errorf("Expected retry to be logged at WARN, got %v", retry["level"])
+	}
+	if retry[taskflow.FieldAttempt] != float64(1) {
+		t.Errorf("Expected attempt 1, got %v", retry[taskflow.FieldAttempt])
+	}
+	if retry["error"] != "temporary failure" {
+		t.Errorf("Expected error 'temporary failure', got %v", retry["error"])
+	}
+	if records[2][taskflow.FieldAttempt] != float64(2) {
+		t.Errorf("Expected attempt 2, got %v", records[2][taskflow.FieldAttempt])
+	}
+}
+
+func TestSlogLogger_Level(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})
+	logger := taskflow.NewSlogLogger(slog.New(handler))
+
+	logger.LogLevel(context.Background(), taskflow.LevelInfo, "hidden")
+	logger.LogLevel(context.Background(), taskflow.LevelError, "shown", slog.String("key", "value"))
+
+	output := buf.String()
+	if strings.Contains(output, "hidden") {
+		t.Errorf("Expected messages below the handler's level to be dropped, got %q", output)
+	}
+	if !strings.Contains(output, "msg=shown") || !strings.Contains(output, "key=value") {
+		t.Errorf("Expected message with its fields, got %q", output)
+	}
+}
+
+func TestLoggerFunc_TextFallback(t *testing.T) {
+	var logs []string
+	task := taskflow.NewTask("broken", func(ctx context.Context, input any) (string, error) {
+		return "", nil
+	}).After(taskflow.NewTask("dependency", func(ctx context.Context, input any) (string, error) {
+		return "", errors.New("boom")
+	}).WithLogger(taskflow.NoOpLogger{})).WithLogger(taskflow.LoggerFunc(func(args ...any) {
+		logs = append(logs, fmt.Sprint(args...))
+	}))
+
+	_, _ = task.Run(context.Background(), nil)
+
+	if len(logs) != 1 {
+		t.Fatalf("Expected 1 log line, got %d: %v", len(logs), logs)
+	}
+	if !strings.HasPrefix(logs[0], "ERROR task dependency failed task=broken run_id=") {
+		t.Errorf("Expected level, message and task fields, got %q", logs[0])
+	}
+	if !strings.HasSuffix(logs[0], `error=boom`) {
+		t.Errorf("Expected error field, got %q", logs[0])
+	}
+}
+
+func TestTaskWithoutLogger(t *testing.T) {
+	task := &taskflow.Task[any, string]{
+		Name: "no_logger",
+		Fn: func(ctx context.Context, input any) (string, error) {
+			return "", errors.New("failure")
+		},
+	}
+
+	// Should not panic
+	_, err := task.Run(context.Background(), nil)
+	if err == nil {
+		t.Error("Expected error, got nil")
+	}
+}
@@ -0,0 +1,215 @@
+package taskflow
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// EventType identifies a lifecycle transition of a run or a task.
+type EventType string
+
+const (
+	// EventRunStarted is sent when a Runner or Workflow starts a run.
+	EventRunStarted EventType = "run_started"
+	// EventRunFinished is sent when a run completes; the event carries its report.
+	EventRunFinished EventType = "run_finished"
+	// EventTaskStarted is sent when a task's dependencies have completed and
+	// its function is about to be called for the first time.
+	EventTaskStarted EventType = "task_started"
+	// EventTaskRetrying is sent when an attempt failed and the task will be retried.
+	EventTaskRetrying EventType = "task_retrying"
+	// EventTaskSucceeded is sent when a task completes without error.
+	EventTaskSucceeded EventType = "task_succeeded"
+	// EventTaskFailed is sent when a task completes with an error.
+	EventTaskFailed EventType = "task_failed"
+	// EventTaskSkipped is sent when a task won't run, because a dependency
+	// failed or the run was cancelled before the task could start.
+	EventTaskSkipped EventType = "task_skipped"
+	// EventTaskCancelled is sent when a task stops because its context was cancelled.
+	EventTaskCancelled EventType = "task_cancelled"
+	// EventTaskRestored is sent instead of running a task that succeeded in the
+	// run being resumed; the event carries the stored result.
+	EventTaskRestored EventType = "task_restored"
+	// EventAttemptStarted is sent before each call to a task's function.
+	EventAttemptStarted EventType = "attempt_started"
+	// EventAttemptFinished is sent after each call to a task's function.
+	EventAttemptFinished EventType = "attempt_finished"
+	// EventFanOutStarted is sent when a FanOutTask starts its functions; the
+	// event carries their number.
+	EventFanOutStarted EventType = "fanout_started"
+	// EventFanOutItemStarted is sent before a FanOutTask calls one of its functions.
+	EventFanOutItemStarted EventType = "fanout_item_started"
+	// EventFanOutItemFinished is sent after a FanOutTask called one of its functions.
+	EventFanOutItemFinished EventType = "fanout_item_finished"
+)
+
+// Event describes a lifecycle transition. Fields that don't apply to the
+// event's type are left zero.
+type Event struct {
+	Type       EventType
+	RunID      string        // Identifies the execution, see RunReport.RunID
+	Task       string        // Name of the task, empty for run events
+	Time       time.Time     // When the event occurred
+	Start      time.Time     // When the task or run started, for finished events
+	Duration   time.Duration // Time from Start to Time, for finished events
+	Attempt    int           // Attempt number; the one that failed for retries, the total for finished tasks
+	Delay      time.Duration // Delay before the next attempt, for retries
+	Index      int           // Position of the function, for fan-out items
+	Width      int           // Number of functions, for EventFanOutStarted
+	QueueWait  time.Duration // Time the task waited for a worker once ready, for EventTaskStarted
+	InputType  reflect.Type  // Type of the task's input, if known
+	OutputType reflect.Type  // Type of the task's output, if known
+	Err        error
+	Input      any        // Input of the task, for EventTaskStarted
+	Result     any        // Output of a succeeded task
+	Report     *RunReport // Outcome of the run, for EventRunFinished
+
+	// DependencyContexts are the contexts the dependencies of a task started
+	// with, see ContextObserver, for EventTaskStarted.
+	DependencyContexts []context.Context
+}
+
+// Observer is notified of lifecycle events. Tasks call their observers from the
+// goroutine that runs them, so an observer shared by several tasks must be safe
+// for concurrent use. Observe should return quickly, since it delays the task.
+type Observer interface {
+	Observe(ctx context.Context, e Event)
+}
+
+// ContextObserver is an Observer that attaches values to the context of what an
+// event starts, such as a tracing span. StartContext is called instead of Observe
+// for EventRunStarted, EventTaskStarted, EventAttemptStarted and EventFanOutItemStarted.
+// The returned context is passed to the run, task, attempt or fan-out function,
+// and to the observers of the matching finished event.
+type ContextObserver interface {
+	Observer
+	StartContext(ctx context.Context, e Event) context.Context
+}
+
+// ObserverFunc is an adapter to allow the use of ordinary functions as observers.
+type ObserverFunc func(ctx context.Context, e Event)
+
+// Observe calls f(ctx, e).
+func (f ObserverFunc) Observe(ctx context.Context, e Event) {
+	f(ctx, e)
+}
+
+// observable is implemented by executables that have their own observers.
+type observable interface {
+	observers() []Observer
+}
+
+// eventFor returns the event type reporting a task completed with status.
+func eventFor(status TaskStatus) EventType {
+	switch status {
+	case StatusSucceeded:
+		return EventTaskSucceeded
+	case StatusSkipped:
+		return EventTaskSkipped
+	case StatusCancelled:
+		return EventTaskCancelled
+	default:
+		return EventTaskFailed
+	}
+}
+
+// Status returns the status of the task a finished task event reports, or an
+// empty status for other events.
+func (e Event) Status() TaskStatus {
+	switch e.Type {
+	case EventTaskSucceeded, EventTaskRestored:
+		return StatusSucceeded
+	case EventTaskFailed:
+		return StatusFailed
+	case EventTaskSkipped:
+		return StatusSkipped
+	case EventTaskCancelled:
+		return StatusCancelled
+	}
+	return ""
+}
+
+// emit sends a task event about e to the observers of the run and to e's own
+// observers, filling in the run ID, the task's name and types, and the time.
+// It does nothing outside of an execution.
+func (x *execution) emit(ctx context.Context, e Executable, ev Event) {
+	x.notify(ctx, e, ev, false)
+}
+
+// start is like emit for an event that starts something. It returns the
+// context to run it with, see ContextObserver.
+func (x *execution) start(ctx context.Context, e Executable, ev Event) context.Context {
+	return x.notify(ctx, e, ev, true)
+}
+
+// notify sends ev to the observers of the run and, if e isn't nil, to e's own
+// observers. When starting, context observers can replace the context.
+func (x *execution) notify(ctx context.Context, e Executable, ev Event, starting bool) context.Context {
+	if x == nil {
+		return ctx
+	}
+
+	ev.RunID = x.id
+	if e != nil {
+		ev.Task = nodeName(e)
+		if typed, ok := e.(Typed); ok {
+			ev.InputType, ev.OutputType = typed.InputType(), typed.OutputType()
+		}
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	observers := x.observers
+	if obs, ok := e.(observable); ok {
+		observers = append(observers[:len(observers):len(observers)], obs.observers()...)
+	}
+	for _, o := range observers {
+		if co, ok := o.(ContextObserver); ok && starting {
+			ctx = co.StartContext(ctx, ev)
+			continue
+		}
+		o.Observe(ctx, ev)
+	}
+	return ctx
+}
+
+// dependencyContexts returns the contexts the dependencies of e that ran in
+// this execution started with.
+func (x *execution) dependencyContexts(e Executable) []context.Context {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	var ctxs []context.Context
+	for _, dep := range dependenciesOf(e) {
+		if st, ok := x.states[dep]; ok && st.ctx != nil {
+			ctxs = append(ctxs, st.ctx)
+		}
+	}
+	return ctxs
+}
+
+// startTask sends EventTaskStarted for e and keeps the returned context for
+// the event that reports its completion.
+func (x *execution) startTask(ctx context.Context, e Executable, ev Event) context.Context {
+	if x == nil {
+		return ctx
+	}
+
+	ev.Type = EventTaskStarted
+	ev.DependencyContexts = x.dependencyContexts(e)
+	x.mu.Lock()
+	if st, ok := x.states[e]; ok {
+		ev.QueueWait = st.queueWait
+	}
+	x.mu.Unlock()
+	ctx = x.start(ctx, e, ev)
+
+	x.mu.Lock()
+	if st, ok := x.states[e]; ok {
+		st.ctx = ctx
+	}
+	x.mu.Unlock()
+	return ctx
+}
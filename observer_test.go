@@ -0,0 +1,273 @@
+package taskflow_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/josuedeavila/taskflow"
+)
+
+// eventRecorder collects the events it observes.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []taskflow.Event
+}
+
+func (r *eventRecorder) Observe(ctx context.Context, e taskflow.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+// types returns the types of the events about the given task, in order.
+func (r *eventRecorder) types(task string) []taskflow.EventType {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var types []taskflow.EventType
+	for _, e := range r.events {
+		if e.Task == task {
+			types = append(types, e.Type)
+		}
+	}
+	return types
+}
+
+func TestRunnerWithObserver(t *testing.T) {
+	recorder := &eventRecorder{}
+	failure := errors.New("failure")
+
+	fetch := taskflow.NewTask("fetch", func(ctx context.Context, input any) (string, error) {
+		return "data", nil
+	}).WithLogger(taskflow.NoOpLogger{})
+	flaky := taskflow.NewTask("flaky", func(ctx context.Context, input string) (int, error) {
+		return 0, failure
+	}).WithRetry(taskflow.RetryPolicy{
+		MaxRetries: 1,
+		Strategy:   taskflow.ConstantBackoff,
+		BaseDelay:  time.Millisecond,
+	}).WithLogger(taskflow.NoOpLogger{}).After(fetch)
+	store := taskflow.NewTask("store", func(ctx context.Context, input int) (any, error) {
+		return nil, nil
+	}).WithLogger(taskflow.NoOpLogger{}).After(flaky)
+
+	runner := taskflow.NewRunner().WithObserver(recorder)
+	runner.Add(store)
+
+	report, err := runner.Run(context.Background())
+	if !errors.Is(err, failure) {
+		t.Fatalf("Expected failure, got %v", err)
+	}
+
+	tests := map[string][]taskflow.EventType{
+		"fetch": {
+			taskflow.EventTaskStarted,
+			taskflow.EventAttemptStarted, taskflow.EventAttemptFinished,
+			taskflow.EventTaskSucceeded,
+		},
+		"flaky": {
+			taskflow.EventTaskStarted,
+			taskflow.EventAttemptStarted, taskflow.EventAttemptFinished,
+			taskflow.EventTaskRetrying,
+			taskflow.EventAttemptStarted, taskflow.EventAttemptFinished,
+			taskflow.EventTaskFailed,
+		},
+		"store": {taskflow.EventTaskSkipped},
+	}
+	for task, expected := range tests {
+		if got := recorder.types(task); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected events %v for %s, got %v", expected, task, got)
+		}
+	}
+
+	first, last := recorder.events[0], recorder.events[len(recorder.events)-1]
+	if first.Type != taskflow.EventRunStarted {
+		t.Errorf("Expected first event %s, got %s", taskflow.EventRunStarted, first.Type)
+	}
+	if last.Type != taskflow.EventRunFinished {
+		t.Fatalf("Expected last event %s, got %s", taskflow.EventRunFinished, last.Type)
+	}
+	if last.Report != report || !errors.Is(last.Err, failure) {
+		t.Errorf("Expected run finished event with the report and error, got %+v", last)
+	}
+
+	for _, e := range recorder.events {
+		if e.RunID != report.RunID {
+			t.Errorf("Expected run ID %s, got %s for %s", report.RunID, e.RunID, e.Type)
+		}
+		if e.Time.IsZero() {
+			t.Errorf("Expected event time for %s", e.Type)
+		}
+
+		switch e.Type {
+		case taskflow.EventTaskRetrying:
+			if e.Attempt != 1 || e.Delay != time.Millisecond || !errors.Is(e.Err, failure) {
+				t.Errorf("Expected retry after attempt 1 in 1ms with failure, got %+v", e)
+			}
+		case taskflow.EventTaskFailed:
+			if e.Attempt != 2 || !errors.Is(e.Err, failure) || e.Duration <= 0 {
+				t.Errorf("Expected failure after 2 attempts with a duration, got %+v", e)
+			}
+			if e.InputType != reflect.TypeFor[string]() || e.OutputType != reflect.TypeFor[int]() {
+				t.Errorf("Expected types string and int, got %v and %v", e.InputType, e.OutputType)
+			}
+		case taskflow.EventTaskStarted:
+			if e.Task == "flaky" && e.Input != "data" {
+				t.Errorf("Expected input 'data', got %v", e.Input)
+			}
+		case taskflow.EventTaskSucceeded:
+			if e.Result != "data" {
+				t.Errorf("Expected result 'data', got %v", e.Result)
+			}
+		}
+	}
+}
+
+func TestTaskWithObserver(t *testing.T) {
+	var events []string
+
+	task := taskflow.NewTask("double", func(ctx context.Context, input int) (int, error) {
+		return input * 2, nil
+	}).WithObserver(taskflow.ObserverFunc(func(ctx context.Context, e taskflow.Event) {
+		events = append(events, fmt.Sprintf("%s:%s", e.Task, e.Type))
+	}))
+
+	if _, err := task.Run(context.Background(), 21); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := task.Run(context.Background(), 1); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"double:task_started", "double:attempt_started", "double:attempt_finished", "double:task_succeeded",
+		"double:task_started", "double:attempt_started", "double:attempt_finished", "double:task_succeeded",
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected events %v, got %v", expected, events)
+	}
+}
+
+func TestTaskWithObserverDependencyFailed(t *testing.T) {
+	recorder := &eventRecorder{}
+
+	dep := taskflow.NewTask("dep", func(ctx context.Context, input any) (any, error) {
+		return nil, errors.New("failure")
+	}).WithLogger(taskflow.NoOpLogger{})
+	task := taskflow.NewTask("task", func(ctx context.Context, input any) (any, error) {
+		return nil, nil
+	}).WithLogger(taskflow.NoOpLogger{}).WithObserver(recorder).After(dep)
+
+	_, _ = task.Run(context.Background(), nil)
+
+	// Only the task's own events are sent to its observers, and a task whose
+	// dependency failed never starts.
+	expected := []taskflow.EventType{taskflow.EventTaskSkipped}
+	if got := recorder.types("task"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected events %v, got %v", expected, got)
+	}
+	if len(recorder.events) != 1 {
+		t.Errorf("Expected 1 event, got %d", len(recorder.events))
+	}
+}
+
+type spanKey struct{}
+
+// spanObserver attaches the name of what each event starts to the context.
+type spanObserver struct {
+	eventRecorder
+}
+
+func (o *spanObserver) StartContext(ctx context.Context, e taskflow.Event) context.Context {
+	o.Observe(ctx, e)
+	return context.WithValue(ctx, spanKey{}, fmt.Sprintf("%s/%s", e.Type, e.Task))
+}
+
+func TestRunnerWithContextObserver(t *testing.T) {
+	observer := &spanObserver{}
+
+	var seen any
+	fetch := taskflow.NewTask("fetch", func(ctx context.Context, input any) ([]int, error) {
+		return []int{1, 2, 3}, nil
+	})
+	fan := &taskflow.FanOutTask[int, int]{
+		Name: "square",
+		Map: func(ctx context.Context, input int) (int, error) {
+			if input == 1 {
+				seen = ctx.Value(spanKey{})
+			}
+			return input * input, nil
+		},
+		FanIn: func(ctx context.Context, results []int) (int, error) {
+			return len(results), nil
+		},
+		MaxConcurrency: 1,
+	}
+	task := fan.ToTask().After(fetch)
+
+	runner := taskflow.NewRunner().WithObserver(observer)
+	runner.Add(task)
+
+	if _, err := runner.Run(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if seen != "fanout_item_started/square" {
+		t.Errorf("Expected the function's context to come from the observer, got %v", seen)
+	}
+
+	counts := make(map[taskflow.EventType]int)
+	for _, e := range observer.events {
+		counts[e.Type]++
+		switch e.Type {
+		case taskflow.EventFanOutStarted:
+			if e.Width != 3 {
+				t.Errorf("Expected fan-out width 3, got %d", e.Width)
+			}
+		case taskflow.EventTaskStarted:
+			if e.Task != "square" {
+				continue
+			}
+			if len(e.DependencyContexts) != 1 || e.DependencyContexts[0].Value(spanKey{}) != "task_started/fetch" {
+				t.Errorf("Expected the context fetch started with, got %v", e.DependencyContexts)
+			}
+		}
+	}
+	if counts[taskflow.EventFanOutItemStarted] != 3 || counts[taskflow.EventFanOutItemFinished] != 3 {
+		t.Errorf("Expected 3 fan-out items started and finished, got %v", counts)
+	}
+}
+
+func TestRunnerWithObserverQueueWait(t *testing.T) {
+	recorder := &eventRecorder{}
+
+	runner := taskflow.NewRunner().WithMaxConcurrency(1).WithObserver(recorder)
+	for i := range 2 {
+		runner.Add(taskflow.NewTask(fmt.Sprintf("task%d", i), func(ctx context.Context, _ any) (any, error) {
+			time.Sleep(20 * time.Millisecond)
+			return nil, nil
+		}))
+	}
+
+	if _, err := runner.Run(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var waits []time.Duration
+	for _, e := range recorder.events {
+		if e.Type == taskflow.EventTaskStarted {
+			waits = append(waits, e.QueueWait)
+		}
+	}
+	if len(waits) != 2 {
+		t.Fatalf("Expected 2 started events, got %d", len(waits))
+	}
+	// With a single worker, the second task waits for the first one
+	if waits[1] < 15*time.Millisecond {
+		t.Errorf("Expected the second task to wait for a worker, got %v", waits)
+	}
+}
@@ -0,0 +1,39 @@
+package taskflow
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is returned when a task function, or a function of a FanOutTask,
+// panics. It carries the recovered value and the stack of the panic.
+type PanicError struct {
+	Task  string
+	Value any    // Value passed to panic
+	Stack []byte // Stack trace of the panicking goroutine, see debug.Stack
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("task %s panicked: %v", e.Task, e.Value)
+}
+
+// Unwrap returns the recovered value if it is an error, so that errors.Is and
+// errors.As see through the panic.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// safeCall calls fn, converting a panic into a *PanicError for task unless
+// repanic is set, in which case the panic is left to crash the program.
+func safeCall[In any, Out any](ctx context.Context, task string, repanic bool, fn TaskFunc[In, Out], input In) (out Out, err error) {
+	if !repanic {
+		defer func() {
+			if v := recover(); v != nil {
+				err = &PanicError{Task: task, Value: v, Stack: debug.Stack()}
+			}
+		}()
+	}
+	return fn(ctx, input)
+}
@@ -0,0 +1,112 @@
+package taskflow_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/josuedeavila/taskflow"
+)
+
+func TestTaskRunRecoversPanic(t *testing.T) {
+	task := taskflow.NewTask("panicking", func(ctx context.Context, input any) (string, error) {
+		panic("something went wrong")
+	}).WithLogger(taskflow.NoOpLogger{})
+	dependent := taskflow.NewTask("dependent", func(ctx context.Context, input string) (string, error) {
+		return input, nil
+	}).WithLogger(taskflow.NoOpLogger{}).After(task)
+
+	runner := taskflow.NewRunner()
+	runner.Add(dependent)
+
+	report, err := runner.Run(context.Background())
+
+	var panicErr *taskflow.PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("Expected PanicError, got %v", err)
+	}
+	if panicErr.Task != "panicking" {
+		t.Errorf("Expected task 'panicking', got '%s'", panicErr.Task)
+	}
+	if panicErr.Value != "something went wrong" {
+		t.Errorf("Expected recovered value, got %v", panicErr.Value)
+	}
+	if !strings.Contains(string(panicErr.Stack), "panic_test.go") {
+		t.Errorf("Expected stack of the panic, got %s", panicErr.Stack)
+	}
+	if panicErr.Error() != "task panicking panicked: something went wrong" {
+		t.Errorf("Unexpected error message: %s", panicErr.Error())
+	}
+
+	if rec, _ := report.Task("panicking"); rec.Status != taskflow.StatusFailed {
+		t.Errorf("Expected panicking task to fail, got %s", rec.Status)
+	}
+	if rec, _ := report.Task("dependent"); rec.Status != taskflow.StatusSkipped {
+		t.Errorf("Expected dependent task to be skipped, got %s", rec.Status)
+	}
+}
+
+func TestTaskRunRecoversPanicWithError(t *testing.T) {
+	task := taskflow.NewTask("panicking", func(ctx context.Context, input any) (string, error) {
+		panic(io.ErrUnexpectedEOF)
+	}).WithLogger(taskflow.NoOpLogger{})
+
+	_, err := task.Run(context.Background(), nil)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected the panic's error to be wrapped, got %v", err)
+	}
+}
+
+func TestTaskWithRepanic(t *testing.T) {
+	task := taskflow.NewTask("panicking", func(ctx context.Context, input any) (string, error) {
+		panic("something went wrong")
+	}).WithLogger(taskflow.NoOpLogger{}).WithRepanic(true)
+
+	defer func() {
+		if v := recover(); v != "something went wrong" {
+			t.Errorf("Expected the original panic, got %v", v)
+		}
+	}()
+
+	_, _ = task.Run(context.Background(), nil)
+	t.Error("Expected task to panic")
+}
+
+func TestFanOutTaskRecoversPanic(t *testing.T) {
+	fanOut := &taskflow.FanOutTask[int, int]{
+		Name: "panicking_fanout",
+		Map: func(ctx context.Context, input int) (int, error) {
+			if input == 2 {
+				var m map[string]int
+				m["boom"] = input // Panics with a runtime error
+			}
+			return input, nil
+		},
+		FanInResults: func(ctx context.Context, results []taskflow.Result[int]) (int, error) {
+			for _, r := range results {
+				if r.Err != nil {
+					return r.Index, r.Err
+				}
+			}
+			return -1, nil
+		},
+	}
+
+	index, err := fanOut.ToTask().WithLogger(taskflow.NoOpLogger{}).Run(context.Background(), []int{1, 2, 3})
+
+	var panicErr *taskflow.PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("Expected PanicError, got %v", err)
+	}
+	if index != 1 {
+		t.Errorf("Expected item 1 to fail, got %v", index)
+	}
+	if panicErr.Task != "panicking_fanout" {
+		t.Errorf("Expected task 'panicking_fanout', got '%s'", panicErr.Task)
+	}
+	if _, ok := panicErr.Value.(error); !ok {
+		t.Errorf("Expected runtime error, got %T", panicErr.Value)
+	}
+}
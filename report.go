@@ -0,0 +1,85 @@
+package taskflow
+
+import (
+	"errors"
+	"time"
+)
+
+// TaskStatus is the outcome of a task in a run.
+type TaskStatus string
+
+const (
+	// StatusSucceeded means the task ran and returned no error.
+	StatusSucceeded TaskStatus = "succeeded"
+	// StatusFailed means the task ran and returned an error.
+	StatusFailed TaskStatus = "failed"
+	// StatusSkipped means the task didn't run, because a dependency failed
+	// or the run was cancelled before the task could start.
+	StatusSkipped TaskStatus = "skipped"
+	// StatusCancelled means the task stopped because its context was cancelled.
+	StatusCancelled TaskStatus = "cancelled"
+)
+
+// TaskRecord describes the execution of a single task in a run.
+type TaskRecord struct {
+	Name     string
+	Status   TaskStatus
+	Start    time.Time
+	End      time.Time
+	Duration time.Duration
+	Attempts int // Number of times the task function was called
+	Err      error
+	Result   any
+	Restored bool // The result was restored from a previous run, see Workflow.Resume
+}
+
+// RunReport describes the outcome of every task in a run.
+type RunReport struct {
+	RunID    string // Identifies the execution the report describes
+	Start    time.Time
+	End      time.Time
+	Duration time.Duration
+	Tasks    []TaskRecord // In topological order, dependencies first
+}
+
+// Task returns the record of the task with the given name.
+func (r *RunReport) Task(name string) (TaskRecord, bool) {
+	for _, rec := range r.Tasks {
+		if rec.Name == name {
+			return rec, true
+		}
+	}
+	return TaskRecord{}, false
+}
+
+// Succeeded reports whether every task in the run succeeded.
+func (r *RunReport) Succeeded() bool {
+	for _, rec := range r.Tasks {
+		if rec.Status != StatusSucceeded {
+			return false
+		}
+	}
+	return true
+}
+
+// Err returns the errors of all failed tasks joined with errors.Join, in the
+// order of the report, or the error itself when a single task failed. Every
+// failed task contributes its error, even if another task failed with the same
+// one. Tasks that were skipped or cancelled because of another failure don't.
+func (r *RunReport) Err() error {
+	var errs []error
+	for _, rec := range r.Tasks {
+		if rec.Status == StatusFailed {
+			errs = append(errs, rec.Err)
+		}
+	}
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return errors.Join(errs...)
+	}
+}
@@ -0,0 +1,152 @@
+package taskflow_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josuedeavila/taskflow"
+)
+
+func TestRunnerRunReport(t *testing.T) {
+	expectedErr := errors.New("fetch failed")
+
+	ok := taskflow.NewTask("ok", func(ctx context.Context, input any) (string, error) {
+		time.Sleep(10 * time.Millisecond)
+		return "done", nil
+	})
+	failing := taskflow.NewTask("failing", func(ctx context.Context, input any) (string, error) {
+		return "", expectedErr
+	})
+	dependent := taskflow.NewTask("dependent", func(ctx context.Context, input string) (string, error) {
+		return input, nil
+	}).After(failing)
+
+	runner := taskflow.NewRunner().WithErrorMode(taskflow.ContinueOnError)
+	runner.Add(ok, dependent)
+
+	report, err := runner.Run(context.Background())
+
+	if err != expectedErr {
+		t.Errorf("Expected error %v, got %v", expectedErr, err)
+	}
+	if report == nil {
+		t.Fatal("Expected a report, got nil")
+	}
+	if len(report.Tasks) != 3 {
+		t.Fatalf("Expected 3 task records, got %d", len(report.Tasks))
+	}
+	if report.Succeeded() {
+		t.Error("Expected report not to succeed")
+	}
+
+	tests := []struct {
+		name     string
+		status   taskflow.TaskStatus
+		attempts int
+		err      error
+		result   any
+	}{
+		{name: "ok", status: taskflow.StatusSucceeded, attempts: 1, result: "done"},
+		{name: "failing", status: taskflow.StatusFailed, attempts: 1, err: expectedErr, result: ""},
+		{name: "dependent", status: taskflow.StatusSkipped, attempts: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, found := report.Task(tt.name)
+			if !found {
+				t.Fatalf("Expected a record for task %s", tt.name)
+			}
+			if rec.Status != tt.status {
+				t.Errorf("Expected status %s, got %s", tt.status, rec.Status)
+			}
+			if rec.Attempts != tt.attempts {
+				t.Errorf("Expected %d attempts, got %d", tt.attempts, rec.Attempts)
+			}
+			if rec.Err != tt.err {
+				t.Errorf("Expected error %v, got %v", tt.err, rec.Err)
+			}
+			if rec.Result != tt.result {
+				t.Errorf("Expected result %v, got %v", tt.result, rec.Result)
+			}
+		})
+	}
+
+	rec, _ := report.Task("ok")
+	if rec.Duration < 10*time.Millisecond || !rec.End.After(rec.Start) {
+		t.Errorf("Expected timing of at least 10ms, got %v (start %v, end %v)", rec.Duration, rec.Start, rec.End)
+	}
+}
+
+func TestRunnerRunReportJoinsErrors(t *testing.T) {
+	err1 := errors.New("first failure")
+	err2 := errors.New("second failure")
+
+	runner := taskflow.NewRunner().WithErrorMode(taskflow.ContinueOnError)
+	runner.Add(
+		taskflow.NewTask("first", func(ctx context.Context, input any) (int, error) { return 0, err1 }),
+		taskflow.NewTask("second", func(ctx context.Context, input any) (int, error) { return 0, err2 }),
+	)
+
+	report, err := runner.Run(context.Background())
+
+	if !errors.Is(err, err1) || !errors.Is(err, err2) {
+		t.Errorf("Expected both errors to be joined, got %v", err)
+	}
+	if !errors.Is(report.Err(), err1) || !errors.Is(report.Err(), err2) {
+		t.Errorf("Expected report error to join both errors, got %v", report.Err())
+	}
+}
+
+func TestRunnerRunReportKeepsSameErrors(t *testing.T) {
+	errNotFound := errors.New("not found")
+
+	runner := taskflow.NewRunner().WithErrorMode(taskflow.ContinueOnError)
+	runner.Add(
+		taskflow.NewTask("users", func(ctx context.Context, input any) (int, error) {
+			return 0, fmt.Errorf("load users: %w", errNotFound)
+		}),
+		taskflow.NewTask("orders", func(ctx context.Context, input any) (int, error) { return 0, errNotFound }),
+		taskflow.NewTask("items", func(ctx context.Context, input any) (int, error) { return 0, errNotFound }),
+	)
+
+	_, err := runner.Run(context.Background())
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok || len(joined.Unwrap()) != 3 {
+		t.Fatalf("Expected the errors of all 3 tasks, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "load users: not found") || strings.Count(err.Error(), "not found") != 3 {
+		t.Errorf("Expected every failure in the error, got %q", err)
+	}
+}
+
+func TestRunnerRunReportCancelled(t *testing.T) {
+	expectedErr := errors.New("fail fast")
+
+	failing := taskflow.NewTask("failing", func(ctx context.Context, input any) (string, error) {
+		time.Sleep(10 * time.Millisecond)
+		return "", expectedErr
+	})
+	inFlight := taskflow.NewTask("in_flight", func(ctx context.Context, input any) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+
+	runner := taskflow.NewRunner()
+	runner.Add(failing, inFlight)
+
+	report, err := runner.Run(context.Background())
+
+	if err != expectedErr {
+		t.Errorf("Expected only the failing task's error, got %v", err)
+	}
+	rec, _ := report.Task("in_flight")
+	if rec.Status != taskflow.StatusCancelled {
+		t.Errorf("Expected in-flight task to be cancelled, got %s", rec.Status)
+	}
+}
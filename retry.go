@@ -1,28 +1,144 @@
 package taskflow
 
 import (
-    "context"
-    "time"
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
 )
 
+// BackoffStrategy defines how the delay between attempts grows.
+type BackoffStrategy int
+
+const (
+	// ExponentialBackoff doubles the delay after every attempt: BaseDelay, 2*BaseDelay, 4*BaseDelay...
+	ExponentialBackoff BackoffStrategy = iota
+	// ConstantBackoff waits BaseDelay between all attempts.
+	ConstantBackoff
+	// LinearBackoff grows the delay by BaseDelay after every attempt: BaseDelay, 2*BaseDelay, 3*BaseDelay...
+	LinearBackoff
+	// DecorrelatedJitterBackoff picks a random delay between BaseDelay and three
+	// times the previous delay, which spreads out retries of concurrent callers.
+	DecorrelatedJitterBackoff
+)
+
+// RetryPolicy describes how a failing operation is retried.
+type RetryPolicy struct {
+	MaxRetries int             // Retries after the first attempt
+	Strategy   BackoffStrategy // How the delay grows, ExponentialBackoff by default
+	BaseDelay  time.Duration   // Delay before the first retry
+	MaxDelay   time.Duration   // Upper bound for a single delay, unbounded if zero
+	MaxElapsed time.Duration   // Total time budget for all attempts, unbounded if zero
+
+	// RetryIf reports whether an error is worth retrying. All errors are retried if nil.
+	RetryIf func(err error) bool
+	// OnRetry is called before each retry with the number of the upcoming attempt
+	// (starting at 2), the delay before it and the error that caused it.
+	OnRetry func(attempt int, delay time.Duration, err error)
+}
 
 // Retry executes a function with retries and exponential backoff.
 // It will retry the function up to 'retries' times, doubling the backoff duration each time.
 // If the context is done before the function succeeds, it returns the context's error.
 func Retry(ctx context.Context, fn func(context.Context) error, retries int, backoff time.Duration) error {
-    var err error
-    for i := 0; i <= retries; i++ {
-        err = fn(ctx)
-        if err == nil {
-            return nil
-        }
-
-        select {
-        case <-ctx.Done():
-            return ctx.Err()
-        case <-time.After(backoff):
-            backoff *= 2
-        }
-    }
-    return err
+	policy := RetryPolicy{MaxRetries: retries, Strategy: ExponentialBackoff, BaseDelay: backoff}
+	return policy.Do(ctx, fn)
+}
+
+// Do calls fn until it succeeds, returns a permanent or non-retryable error, or the
+// policy runs out of retries or time. It returns the last error fn returned.
+// If the context is done while waiting for a retry, it returns the context's error.
+func (p RetryPolicy) Do(ctx context.Context, fn func(context.Context) error) error {
+	start := time.Now()
+	var delay time.Duration
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		var permanent *PermanentError
+		if errors.As(err, &permanent) {
+			return permanent.Err
+		}
+		if attempt > p.MaxRetries || (p.RetryIf != nil && !p.RetryIf(err)) {
+			return err
+		}
+
+		delay = p.Delay(attempt, delay)
+		if p.MaxElapsed > 0 && time.Since(start)+delay > p.MaxElapsed {
+			return err
+		}
+
+		if p.OnRetry != nil {
+			p.OnRetry(attempt+1, delay, err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Delay returns the delay to wait after the given failed attempt (starting at 1).
+// prev is the previous delay, used by DecorrelatedJitterBackoff.
+func (p RetryPolicy) Delay(attempt int, prev time.Duration) time.Duration {
+	var delay time.Duration
+
+	switch p.Strategy {
+	case ConstantBackoff:
+		delay = p.BaseDelay
+	case LinearBackoff:
+		delay = multiplyDelay(p.BaseDelay, float64(attempt))
+	case DecorrelatedJitterBackoff:
+		upper := multiplyDelay(max(prev, p.BaseDelay), 3)
+		delay = p.BaseDelay
+		if upper > p.BaseDelay {
+			delay += rand.N(upper - p.BaseDelay)
+		}
+	default:
+		delay = multiplyDelay(p.BaseDelay, math.Pow(2, float64(attempt-1)))
+	}
+
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// multiplyDelay multiplies d by factor, saturating instead of overflowing.
+func multiplyDelay(d time.Duration, factor float64) time.Duration {
+	result := float64(d) * factor
+	if result >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(result)
+}
+
+// PermanentError wraps an error that must not be retried.
+type PermanentError struct {
+	Err error
+}
+
+// Permanent wraps err so that RetryPolicy.Do stops retrying immediately and
+// returns err. It returns nil if err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
 }
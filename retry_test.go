@@ -268,3 +268,167 @@ func TestRetry_ImmediateContextCancellation(t *testing.T) {
 		t.Errorf("Expected function to be called once, was called %d times", callCount)
 	}
 }
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	base := 10 * time.Millisecond
+
+	tests := []struct {
+		name     string
+		policy   taskflow.RetryPolicy
+		expected []time.Duration
+	}{
+		{
+			name:     "constant",
+			policy:   taskflow.RetryPolicy{Strategy: taskflow.ConstantBackoff, BaseDelay: base},
+			expected: []time.Duration{base, base, base, base},
+		},
+		{
+			name:     "linear",
+			policy:   taskflow.RetryPolicy{Strategy: taskflow.LinearBackoff, BaseDelay: base},
+			expected: []time.Duration{base, 2 * base, 3 * base, 4 * base},
+		},
+		{
+			name:     "exponential",
+			policy:   taskflow.RetryPolicy{Strategy: taskflow.ExponentialBackoff, BaseDelay: base},
+			expected: []time.Duration{base, 2 * base, 4 * base, 8 * base},
+		},
+		{
+			name:     "exponential with max delay",
+			policy:   taskflow.RetryPolicy{Strategy: taskflow.ExponentialBackoff, BaseDelay: base, MaxDelay: 3 * base},
+			expected: []time.Duration{base, 2 * base, 3 * base, 3 * base},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var prev time.Duration
+			for i, want := range tt.expected {
+				got := tt.policy.Delay(i+1, prev)
+				if got != want {
+					t.Errorf("Attempt %d: expected delay %v, got %v", i+1, want, got)
+				}
+				prev = got
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_DecorrelatedJitter(t *testing.T) {
+	base := 10 * time.Millisecond
+	policy := taskflow.RetryPolicy{Strategy: taskflow.DecorrelatedJitterBackoff, BaseDelay: base, MaxDelay: time.Second}
+
+	prev := time.Duration(0)
+	for attempt := 1; attempt <= 20; attempt++ {
+		delay := policy.Delay(attempt, prev)
+		upper := 3 * max(prev, base)
+		if delay < base || delay > upper || delay > time.Second {
+			t.Errorf("Attempt %d: expected delay in [%v, %v], got %v", attempt, base, min(upper, time.Second), delay)
+		}
+		prev = delay
+	}
+}
+
+func TestRetryPolicy_RetryIf(t *testing.T) {
+	retryable := errors.New("retryable")
+	fatal := errors.New("fatal")
+	callCount := 0
+
+	policy := taskflow.RetryPolicy{
+		MaxRetries: 5,
+		BaseDelay:  time.Millisecond,
+		RetryIf: func(err error) bool {
+			return errors.Is(err, retryable)
+		},
+	}
+
+	err := policy.Do(context.Background(), func(ctx context.Context) error {
+		callCount++
+		if callCount < 3 {
+			return retryable
+		}
+		return fatal
+	})
+
+	if err != fatal {
+		t.Errorf("Expected error %v, got %v", fatal, err)
+	}
+	if callCount != 3 {
+		t.Errorf("Expected 3 calls, got %d", callCount)
+	}
+}
+
+func TestRetryPolicy_Permanent(t *testing.T) {
+	expectedErr := errors.New("bad request")
+	callCount := 0
+
+	policy := taskflow.RetryPolicy{MaxRetries: 5, BaseDelay: time.Millisecond}
+	err := policy.Do(context.Background(), func(ctx context.Context) error {
+		callCount++
+		return taskflow.Permanent(expectedErr)
+	})
+
+	if err != expectedErr {
+		t.Errorf("Expected unwrapped error %v, got %v", expectedErr, err)
+	}
+	if callCount != 1 {
+		t.Errorf("Expected 1 call, got %d", callCount)
+	}
+	if taskflow.Permanent(nil) != nil {
+		t.Error("Expected Permanent(nil) to be nil")
+	}
+}
+
+func TestRetryPolicy_MaxElapsed(t *testing.T) {
+	expectedErr := errors.New("still failing")
+	callCount := 0
+
+	policy := taskflow.RetryPolicy{
+		MaxRetries: 100,
+		Strategy:   taskflow.ConstantBackoff,
+		BaseDelay:  20 * time.Millisecond,
+		MaxElapsed: 70 * time.Millisecond,
+	}
+
+	start := time.Now()
+	err := policy.Do(context.Background(), func(ctx context.Context) error {
+		callCount++
+		return expectedErr
+	})
+	duration := time.Since(start)
+
+	if err != expectedErr {
+		t.Errorf("Expected error %v, got %v", expectedErr, err)
+	}
+	if callCount < 2 || callCount > 4 {
+		t.Errorf("Expected the time budget to allow 2 to 4 calls, got %d", callCount)
+	}
+	if duration > 100*time.Millisecond {
+		t.Errorf("Expected retries to stop within the budget, took %v", duration)
+	}
+}
+
+func TestRetryPolicy_OnRetry(t *testing.T) {
+	var attempts []int
+	var delays []time.Duration
+
+	policy := taskflow.RetryPolicy{
+		MaxRetries: 2,
+		Strategy:   taskflow.LinearBackoff,
+		BaseDelay:  time.Millisecond,
+		OnRetry: func(attempt int, delay time.Duration, err error) {
+			attempts = append(attempts, attempt)
+			delays = append(delays, delay)
+		},
+	}
+
+	_ = policy.Do(context.Background(), func(ctx context.Context) error {
+		return errors.New("fail")
+	})
+
+	if len(attempts) != 2 || attempts[0] != 2 || attempts[1] != 3 {
+		t.Errorf("Expected OnRetry for attempts [2 3], got %v", attempts)
+	}
+	if len(delays) != 2 || delays[0] != time.Millisecond || delays[1] != 2*time.Millisecond {
+		t.Errorf("Expected delays [1ms 2ms], got %v", delays)
+	}
+}
@@ -2,12 +2,29 @@ package taskflow
 
 import (
 	"context"
-	"sync"
+	"fmt"
+)
+
+// ErrorMode controls how a Runner reacts to a failing task.
+type ErrorMode int
+
+const (
+	// FailFast cancels the context shared by all tasks as soon as one of them fails.
+	// Tasks that haven't started yet are skipped and running tasks see ctx.Done().
+	FailFast ErrorMode = iota
+	// ContinueOnError keeps running every task whose dependencies succeeded,
+	// which suits best-effort batches.
+	ContinueOnError
 )
 
 // Runner is a simple task runner that executes tasks concurrently.
 type Runner struct {
-	Tasks []Executable
+	Tasks          []Executable
+	ErrorMode      ErrorMode      // How a failing task affects the others, FailFast by default
+	MaxConcurrency int            // Maximum number of tasks running at once, unlimited if zero
+	GroupLimits    map[string]int // Maximum number of tasks running at once per tag
+	Observers      []Observer     // Notified of the run's events and those of every task in it
+	Store          StateStore     // Persists the outcome of tasks so that runs can be resumed
 }
 
 // NewRunner creates a new Runner instance.
@@ -20,30 +37,98 @@ func (r *Runner) Add(tasks ...Executable) {
 	r.Tasks = append(r.Tasks, tasks...)
 }
 
-// Run executes all tasks concurrently, respecting their dependencies.
-// It returns the first error encountered during execution, or nil if all tasks succeed.
-// If a task has dependencies, it will wait for all dependencies to complete before executing.
-// If any task returns an error, it stops execution and returns that error.
-func (r *Runner) Run(ctx context.Context) error {
-	var wg sync.WaitGroup
-	errors := make(chan error, len(r.Tasks))
-
-	for _, t := range r.Tasks {
-		wg.Add(1)
-		go func(t Executable) {
-			defer wg.Done()
-			if _, err := t.Run(ctx, nil); err != nil {
-				errors <- err
-			}
-		}(t)
+// WithErrorMode sets how the runner reacts to a failing task.
+func (r *Runner) WithErrorMode(mode ErrorMode) *Runner {
+	r.ErrorMode = mode
+	return r
+}
+
+// WithMaxConcurrency limits the number of tasks running at once. Ready tasks
+// are executed by a fixed pool of n workers; zero means one worker per task.
+// Dependencies of SequentialDependencies tasks run inside the task that chains
+// them and don't take a worker of their own.
+func (r *Runner) WithMaxConcurrency(n int) *Runner {
+	r.MaxConcurrency = n
+	return r
+}
+
+// WithGroupLimit limits the number of tasks tagged with group that run at once.
+// The limit must be at least 1, or the runner's workflow is rejected.
+// Like MaxConcurrency, it doesn't apply to the dependencies of SequentialDependencies
+// tasks, which run inside the task that chains them.
+func (r *Runner) WithGroupLimit(group string, n int) *Runner {
+	if r.GroupLimits == nil {
+		r.GroupLimits = make(map[string]int)
+	}
+	r.GroupLimits[group] = n
+	return r
+}
+
+// WithObserver adds observers notified when a run starts and finishes, and at
+// each lifecycle transition of every task in the run.
+func (r *Runner) WithObserver(observers ...Observer) *Runner {
+	r.Observers = append(r.Observers, observers...)
+	return r
+}
+
+// WithStateStore persists the outcome of every task to store as it completes,
+// so that an interrupted run can be resumed, see Resume.
+func (r *Runner) WithStateStore(store StateStore) *Runner {
+	r.Store = store
+	return r
+}
+
+// Graph builds and validates the dependency graph of the runner's tasks.
+func (r *Runner) Graph() (*Graph, error) {
+	return NewGraph(r.Tasks...)
+}
+
+// Workflow validates the runner's tasks and returns a reusable workflow with the
+// runner's current settings. Later changes to the runner don't affect it.
+func (r *Runner) Workflow() (*Workflow, error) {
+	g, err := r.Graph()
+	if err != nil {
+		return nil, err
+	}
+	for group, limit := range r.GroupLimits {
+		if limit < 1 {
+			// Tasks of the group could never start.
+			return nil, fmt.Errorf("taskflow: group %q: limit must be at least 1, got %d", group, limit)
+		}
 	}
 
-	wg.Wait()
-	close(errors)
+	w := &Workflow{
+		graph:          g,
+		errorMode:      r.ErrorMode,
+		maxConcurrency: r.MaxConcurrency,
+		groupLimits:    make(map[string]int, len(r.GroupLimits)),
+		observers:      append([]Observer(nil), r.Observers...),
+		store:          r.Store,
+	}
+	for group, limit := range r.GroupLimits {
+		w.groupLimits[group] = limit
+	}
+	return w, nil
+}
 
-	for err := range errors {
-		return err
+// Run executes all tasks concurrently, respecting their dependencies, and returns
+// a report with the outcome of every task. See Workflow.Run for details.
+// The dependency graph is validated first; if it is invalid, no task runs and the
+// report is nil.
+func (r *Runner) Run(ctx context.Context) (*RunReport, error) {
+	w, err := r.Workflow()
+	if err != nil {
+		return nil, err
 	}
+	return w.Run(ctx, nil)
+}
 
-	return nil
+// Resume continues the run with the given ID, skipping the tasks that already
+// succeeded in it. See Workflow.Resume for details.
+func (r *Runner) Resume(ctx context.Context, runID string) (*RunReport, error) {
+	w, err := r.Workflow()
+	if err != nil {
+		return nil, err
+	}
+	return w.Resume(ctx, runID, nil)
 }
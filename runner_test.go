@@ -3,7 +3,10 @@ package taskflow_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -84,7 +87,7 @@ func TestRunnerRunSuccess(t *testing.T) {
 
 	runner.Add(task1, task2)
 
-	err := runner.Run(context.Background())
+	_, err := runner.Run(context.Background())
 
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
@@ -126,7 +129,7 @@ func TestRunnerRunWithError(t *testing.T) {
 
 	runner.Add(task1, task2)
 
-	err := runner.Run(context.Background())
+	_, err := runner.Run(context.Background())
 
 	if err == nil {
 		t.Error("Expected error but got none")
@@ -153,7 +156,7 @@ func TestRunnerRunWithTimeout(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
 	defer cancel()
 
-	err := runner.Run(ctx)
+	_, err := runner.Run(ctx)
 
 	if err == nil {
 		t.Error("Expected timeout error but got none")
@@ -170,7 +173,7 @@ func TestRunnerRunConcurrency(t *testing.T) {
 
 	for i := 0; i < numTasks; i++ {
 		i := i
-		task := taskflow.NewTask("task", func(ctx context.Context, input any) (int, error) {
+		task := taskflow.NewTask(fmt.Sprintf("task%d", i), func(ctx context.Context, input any) (int, error) {
 			time.Sleep(100 * time.Millisecond) // Simulate work
 			mu.Lock()
 			executionTimes[i] = time.Now()
@@ -180,7 +183,7 @@ func TestRunnerRunConcurrency(t *testing.T) {
 		runner.Add(task)
 	}
 
-	err := runner.Run(context.Background())
+	_, err := runner.Run(context.Background())
 	totalTime := time.Since(start)
 
 	if err != nil {
@@ -215,7 +218,7 @@ func TestRunnerRunConcurrency(t *testing.T) {
 func TestRunnerRunEmpty(t *testing.T) {
 	runner := taskflow.NewRunner()
 
-	err := runner.Run(context.Background())
+	_, err := runner.Run(context.Background())
 
 	if err != nil {
 		t.Errorf("Expected no error for empty runner, got %v", err)
@@ -252,7 +255,7 @@ func TestRunnerRunWithDependencies(t *testing.T) {
 
 	runner.Add(task2, task3) // Note: task1 is a dependency of task2
 
-	err := runner.Run(context.Background())
+	_, err := runner.Run(context.Background())
 
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
@@ -300,7 +303,7 @@ func TestRunnerRunStopsOnFirstError(t *testing.T) {
 	// Create multiple tasks, some will fail
 	for i := 0; i < 5; i++ {
 		i := i
-		task := taskflow.NewTask("task", func(ctx context.Context, input any) (int, error) {
+		task := taskflow.NewTask(fmt.Sprintf("task%d", i), func(ctx context.Context, input any) (int, error) {
 			mu.Lock()
 			executionCount++
 			mu.Unlock()
@@ -314,7 +317,7 @@ func TestRunnerRunStopsOnFirstError(t *testing.T) {
 		runner.Add(task)
 	}
 
-	err := runner.Run(context.Background())
+	_, err := runner.Run(context.Background())
 
 	if err == nil {
 		t.Error("Expected error but got none")
@@ -364,7 +367,7 @@ func TestRunnerRunWithMockTasks(t *testing.T) {
 
 	runner.Add(mock1, mock2, mock3)
 
-	err := runner.Run(context.Background())
+	_, err := runner.Run(context.Background())
 
 	if err == nil {
 		t.Error("Expected error but got none")
@@ -381,3 +384,285 @@ func TestRunnerRunWithMockTasks(t *testing.T) {
 		t.Error("mock3 was not called")
 	}
 }
+
+func TestRunnerRunRejectsInvalidGraph(t *testing.T) {
+	called := false
+	a := taskflow.NewTask("a", func(ctx context.Context, input any) (string, error) {
+		called = true
+		return "", nil
+	})
+	b := newNamedTask("b").After(a)
+	a.After(b)
+
+	runner := taskflow.NewRunner()
+	runner.Add(a)
+
+	_, err := runner.Run(context.Background())
+
+	var cycleErr *taskflow.CycleError
+	if !errors.As(err, &cycleErr) {
+		t.Errorf("Expected CycleError, got %v", err)
+	}
+	if called {
+		t.Error("Expected no task to run on an invalid graph")
+	}
+}
+
+func TestRunnerRunFailFast(t *testing.T) {
+	expectedErr := errors.New("fail fast")
+	var mu sync.Mutex
+	dependentCalled := false
+
+	failing := taskflow.NewTask("failing", func(ctx context.Context, input any) (string, error) {
+		return "", expectedErr
+	})
+
+	slowDep := taskflow.NewTask("slow_dep", func(ctx context.Context, input any) (string, error) {
+		time.Sleep(50 * time.Millisecond)
+		return "dep", nil
+	})
+
+	pending := taskflow.NewTask("pending", func(ctx context.Context, input string) (string, error) {
+		mu.Lock()
+		dependentCalled = true
+		mu.Unlock()
+		return input, nil
+	}).After(slowDep)
+
+	inFlight := taskflow.NewTask("in_flight", func(ctx context.Context, input any) (string, error) {
+		select {
+		case <-time.After(time.Second):
+			return "completed", nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	})
+
+	runner := taskflow.NewRunner()
+	runner.Add(failing, pending, inFlight)
+
+	start := time.Now()
+	_, err := runner.Run(context.Background())
+	duration := time.Since(start)
+
+	if err != expectedErr {
+		t.Errorf("Expected error %v, got %v", expectedErr, err)
+	}
+
+	mu.Lock()
+	if dependentCalled {
+		t.Error("Expected pending task to be skipped after the first failure")
+	}
+	mu.Unlock()
+
+	if !errors.Is(inFlight.Err, context.Canceled) {
+		t.Errorf("Expected in-flight task to be cancelled, got %v", inFlight.Err)
+	}
+
+	if duration > 500*time.Millisecond {
+		t.Errorf("Expected run to stop early, took %v", duration)
+	}
+}
+
+func TestRunnerRunContinueOnError(t *testing.T) {
+	expectedErr := errors.New("best effort")
+
+	failing := taskflow.NewTask("failing", func(ctx context.Context, input any) (string, error) {
+		return "", expectedErr
+	})
+
+	slow := taskflow.NewTask("slow", func(ctx context.Context, input any) (string, error) {
+		select {
+		case <-time.After(50 * time.Millisecond):
+			return "completed", nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	})
+
+	runner := taskflow.NewRunner().WithErrorMode(taskflow.ContinueOnError)
+	runner.Add(failing, slow)
+
+	_, err := runner.Run(context.Background())
+
+	if err != expectedErr {
+		t.Errorf("Expected error %v, got %v", expectedErr, err)
+	}
+	if slow.Err != nil || slow.Result != "completed" {
+		t.Errorf("Expected slow task to complete, got result %v and error %v", slow.Result, slow.Err)
+	}
+}
+
+// concurrencyProbe records the highest number of callers inside it at once.
+type concurrencyProbe struct {
+	current atomic.Int32
+	peak    atomic.Int32
+}
+
+func (p *concurrencyProbe) enter() {
+	n := p.current.Add(1)
+	for {
+		peak := p.peak.Load()
+		if n <= peak || p.peak.CompareAndSwap(peak, n) {
+			return
+		}
+	}
+}
+
+func (p *concurrencyProbe) leave() {
+	p.current.Add(-1)
+}
+
+func TestRunnerRunMaxConcurrency(t *testing.T) {
+	probe := &concurrencyProbe{}
+	runner := taskflow.NewRunner().WithMaxConcurrency(2)
+
+	for i := 0; i < 6; i++ {
+		runner.Add(taskflow.NewTask(fmt.Sprintf("task%d", i), func(ctx context.Context, input any) (int, error) {
+			probe.enter()
+			defer probe.leave()
+			time.Sleep(20 * time.Millisecond)
+			return i, nil
+		}))
+	}
+
+	if _, err := runner.Run(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if peak := probe.peak.Load(); peak != 2 {
+		t.Errorf("Expected at most 2 tasks at once, got %d", peak)
+	}
+}
+
+func TestRunnerRunGroupLimit(t *testing.T) {
+	db := &concurrencyProbe{}
+	all := &concurrencyProbe{}
+	runner := taskflow.NewRunner().WithGroupLimit("db", 2)
+
+	for i := 0; i < 6; i++ {
+		runner.Add(taskflow.NewTask(fmt.Sprintf("query%d", i), func(ctx context.Context, input any) (int, error) {
+			db.enter()
+			all.enter()
+			defer db.leave()
+			defer all.leave()
+			time.Sleep(20 * time.Millisecond)
+			return i, nil
+		}).WithTags("db"))
+	}
+	for i := 0; i < 4; i++ {
+		runner.Add(taskflow.NewTask(fmt.Sprintf("compute%d", i), func(ctx context.Context, input any) (int, error) {
+			all.enter()
+			defer all.leave()
+			time.Sleep(20 * time.Millisecond)
+			return i, nil
+		}))
+	}
+
+	if _, err := runner.Run(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if peak := db.peak.Load(); peak != 2 {
+		t.Errorf("Expected at most 2 db tasks at once, got %d", peak)
+	}
+	if peak := all.peak.Load(); peak < 6 {
+		t.Errorf("Expected untagged tasks to run alongside db tasks, got %d at once", peak)
+	}
+}
+
+func TestRunnerRunSeveralGroupLimits(t *testing.T) {
+	db := &concurrencyProbe{}
+	cache := &concurrencyProbe{}
+	probes := map[string]*concurrencyProbe{"db": db, "cache": cache}
+	var ran atomic.Int32
+	runner := taskflow.NewRunner().WithGroupLimit("db", 2).WithGroupLimit("cache", 1)
+
+	add := func(name string, tags ...string) {
+		runner.Add(taskflow.NewTask(name, func(ctx context.Context, input any) (int, error) {
+			for _, tag := range tags {
+				probes[tag].enter()
+				defer probes[tag].leave()
+			}
+			time.Sleep(5 * time.Millisecond)
+			ran.Add(1)
+			return 0, nil
+		}).WithTags(tags...))
+	}
+	for i := 0; i < 4; i++ {
+		add(fmt.Sprintf("query%d", i), "db")
+		add(fmt.Sprintf("cached%d", i), "db", "cache")
+		add(fmt.Sprintf("lookup%d", i), "cache")
+	}
+
+	if _, err := runner.Run(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if ran.Load() != 12 {
+		t.Errorf("Expected 12 tasks to run, got %d", ran.Load())
+	}
+	if peak := db.peak.Load(); peak > 2 {
+		t.Errorf("Expected at most 2 db tasks at once, got %d", peak)
+	}
+	if peak := cache.peak.Load(); peak != 1 {
+		t.Errorf("Expected at most 1 cache task at once, got %d", peak)
+	}
+}
+
+func TestRunnerRunRejectsEmptyGroupLimit(t *testing.T) {
+	task := taskflow.NewTask("query", func(ctx context.Context, input any) (int, error) {
+		return 1, nil
+	}).WithTags("db")
+	runner := taskflow.NewRunner().WithGroupLimit("db", 0)
+	runner.Add(task)
+
+	report, err := runner.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), `group "db"`) {
+		t.Fatalf("Expected group limit error, got %v", err)
+	}
+	if report != nil {
+		t.Errorf("Expected no report, got %+v", report)
+	}
+}
+
+func TestRunnerRunSingleWorkerWithDependencies(t *testing.T) {
+	var order []string
+	var mu sync.Mutex
+	record := func(name string) {
+		mu.Lock()
+		order = append(order, name)
+		mu.Unlock()
+	}
+
+	a := taskflow.NewTask("a", func(ctx context.Context, input any) (string, error) {
+		record("a")
+		return "a", nil
+	})
+	b := taskflow.NewTask("b", func(ctx context.Context, input string) (string, error) {
+		record("b")
+		return input + "b", nil
+	}).After(a)
+	c := taskflow.NewTask("c", func(ctx context.Context, input string) (string, error) {
+		record("c")
+		return input + "c", nil
+	}).After(a)
+	d := taskflow.NewTask("d", func(ctx context.Context, input []any) (string, error) {
+		record("d")
+		return fmt.Sprint(input...), nil
+	}).After(b, c)
+
+	runner := taskflow.NewRunner().WithMaxConcurrency(1)
+	runner.Add(d)
+
+	if _, err := runner.Run(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(order) != 4 || order[0] != "a" || order[3] != "d" {
+		t.Errorf("Expected a first and d last, got %v", order)
+	}
+	if d.Result != "abac" {
+		t.Errorf("Expected result 'abac', got %v", d.Result)
+	}
+}
@@ -0,0 +1,397 @@
+package taskflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// OverlapPolicy controls what a Schedule does when it is due while a previous
+// run is still in progress.
+type OverlapPolicy int
+
+const (
+	// SkipOverlap skips the run; the schedule is due again at its next time.
+	SkipOverlap OverlapPolicy = iota
+	// QueueOverlap starts the run once the runs in progress complete, so that
+	// runs never overlap. Runs keep queuing while they take longer than the interval.
+	QueueOverlap
+	// AllowOverlap starts the run right away, alongside the runs in progress,
+	// as long as fewer than MaxConcurrency runs are in progress; otherwise the
+	// run is skipped.
+	AllowOverlap
+)
+
+// CatchUpPolicy controls what a Schedule does about the runs it missed, because
+// the scheduler wasn't running since Schedule.LastRun or because the process
+// was suspended past several of its times.
+type CatchUpPolicy int
+
+const (
+	// CatchUpSkip drops the missed runs.
+	CatchUpSkip CatchUpPolicy = iota
+	// CatchUpOnce starts a single run for all the missed ones.
+	CatchUpOnce
+	// CatchUpAll starts a run for each missed time, subject to the overlap
+	// policy; use QueueOverlap or AllowOverlap so that they are not skipped.
+	CatchUpAll
+)
+
+// Trigger determines when a Schedule runs.
+type Trigger interface {
+	// Next returns the first time after the given one at which to run, or the
+	// zero time if there is none.
+	Next(after time.Time) time.Time
+}
+
+// interval is the Trigger of a schedule running at a fixed interval from an
+// anchor time.
+type interval struct {
+	anchor time.Time
+	every  time.Duration
+}
+
+func (i interval) Next(after time.Time) time.Time {
+	if after.Before(i.anchor) {
+		return i.anchor
+	}
+	n := after.Sub(i.anchor)/i.every + 1
+	return i.anchor.Add(n * i.every)
+}
+
+// scheduledKey is the context key of the time a run was scheduled at.
+type scheduledKey struct{}
+
+// ScheduledTime returns the time at which the scheduled run of ctx was due,
+// which differs from the time it started for caught up, queued or jittered runs.
+func ScheduledTime(ctx context.Context) (time.Time, bool) {
+	t, ok := ctx.Value(scheduledKey{}).(time.Time)
+	return t, ok
+}
+
+// Schedule runs a workflow repeatedly, see Scheduler.
+type Schedule struct {
+	Name           string
+	Workflow       *Workflow
+	Trigger        Trigger                            // When to run, see ParseCron; overrides Interval
+	Interval       time.Duration                      // Time between runs without Trigger, the first one starts after one interval
+	Input          any                                // Input of every run, see Workflow.Run
+	Overlap        OverlapPolicy                      // What to do when due while a run is in progress, SkipOverlap by default
+	MaxConcurrency int                                // Maximum number of runs at once with AllowOverlap, unlimited if zero
+	CatchUp        CatchUpPolicy                      // What to do about missed runs, CatchUpSkip by default
+	LastRun        time.Time                          // Time of the last run before the scheduler started, from which runs are missed
+	Jitter         time.Duration                      // Maximum random delay added to each run
+	OnComplete     func(report *RunReport, err error) // Optional function called after each run
+}
+
+// Every returns a schedule running w every interval.
+func Every(name string, interval time.Duration, w *Workflow) *Schedule {
+	return &Schedule{Name: name, Workflow: w, Interval: interval}
+}
+
+// NewSchedule returns a schedule running w at the times of trigger.
+func NewSchedule(name string, trigger Trigger, w *Workflow) *Schedule {
+	return &Schedule{Name: name, Workflow: w, Trigger: trigger}
+}
+
+// WithInput sets the input of every run.
+func (s *Schedule) WithInput(input any) *Schedule {
+	s.Input = input
+	return s
+}
+
+// WithOverlap sets what to do when the schedule is due while a run is in progress.
+func (s *Schedule) WithOverlap(policy OverlapPolicy) *Schedule {
+	s.Overlap = policy
+	return s
+}
+
+// WithMaxConcurrency limits the number of runs at once with AllowOverlap.
+func (s *Schedule) WithMaxConcurrency(n int) *Schedule {
+	s.MaxConcurrency = n
+	return s
+}
+
+// WithCatchUp sets what to do about the runs missed since lastRun, which is
+// typically the time of the last run recorded before a restart, and while the
+// process was suspended. A zero lastRun only catches up the latter.
+func (s *Schedule) WithCatchUp(policy CatchUpPolicy, lastRun time.Time) *Schedule {
+	s.CatchUp = policy
+	s.LastRun = lastRun
+	return s
+}
+
+// WithJitter delays each run by a random duration up to jitter, so that
+// schedules due at the same time don't all start at once. Keep it well below
+// the time between runs, or runs are delayed past the next one and missed.
+func (s *Schedule) WithJitter(jitter time.Duration) *Schedule {
+	s.Jitter = jitter
+	return s
+}
+
+// WithOnComplete sets a function called with the outcome of each run.
+func (s *Schedule) WithOnComplete(fn func(report *RunReport, err error)) *Schedule {
+	s.OnComplete = fn
+	return s
+}
+
+// validate reports whether the schedule can be started.
+func (s *Schedule) validate() error {
+	if s.Workflow == nil {
+		return fmt.Errorf("scheduler: schedule %s has no workflow", s.Name)
+	}
+	if s.Trigger == nil && s.Interval <= 0 {
+		return fmt.Errorf("scheduler: schedule %s has a non-positive interval %v", s.Name, s.Interval)
+	}
+	if s.Jitter < 0 {
+		return fmt.Errorf("scheduler: schedule %s has a negative jitter %v", s.Name, s.Jitter)
+	}
+	return nil
+}
+
+// trigger returns the trigger of the schedule, anchoring intervals at the last
+// run if known, or else at start.
+func (s *Schedule) trigger(start time.Time) Trigger {
+	if s.Trigger != nil {
+		return s.Trigger
+	}
+	if !s.LastRun.IsZero() && s.LastRun.Before(start) {
+		start = s.LastRun
+	}
+	return interval{anchor: start.Add(s.Interval), every: s.Interval}
+}
+
+// Scheduler runs workflows on schedules until it is stopped.
+type Scheduler struct {
+	Schedules []*Schedule
+	Logger    Logger // Optional logger for skipped and failed runs
+	Clock     Clock  // Source of time, the wall clock if nil
+
+	mu         sync.Mutex
+	started    bool
+	stopLoops  context.CancelFunc // Stops triggering runs
+	cancelRuns context.CancelFunc // Cancels the runs in progress
+	loops      sync.WaitGroup
+	runs       sync.WaitGroup
+}
+
+// NewScheduler creates a new Scheduler instance.
+func NewScheduler() *Scheduler {
+	return &Scheduler{Logger: newDefaultLogger()}
+}
+
+// Add adds one or more schedules to the scheduler. Schedules added after Start
+// are not run.
+func (s *Scheduler) Add(schedules ...*Schedule) {
+	s.Schedules = append(s.Schedules, schedules...)
+}
+
+// WithLogger sets the logger for the scheduler.
+func (s *Scheduler) WithLogger(logger Logger) *Scheduler {
+	s.Logger = logger
+	return s
+}
+
+// WithClock sets the source of time of the scheduler, see ManualClock.
+func (s *Scheduler) WithClock(clock Clock) *Scheduler {
+	s.Clock = clock
+	return s
+}
+
+// clock returns the source of time of the scheduler.
+func (s *Scheduler) clock() Clock {
+	if s.Clock == nil {
+		return wallClock{}
+	}
+	return s.Clock
+}
+
+// Start validates the schedules and starts running them in the background. Runs
+// get a context derived from ctx; cancelling it stops the scheduler and cancels
+// the runs in progress. A scheduler can only be started once.
+func (s *Scheduler) Start(ctx context.Context) error {
+	var errs []error
+	for _, sch := range s.Schedules {
+		if err := sch.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return errors.New("scheduler: already started")
+	}
+	s.started = true
+
+	runCtx, cancelRuns := context.WithCancel(ctx)
+	loopCtx, stopLoops := context.WithCancel(runCtx)
+	s.cancelRuns, s.stopLoops = cancelRuns, stopLoops
+
+	for _, sch := range s.Schedules {
+		s.loops.Add(1)
+		go s.loop(loopCtx, runCtx, sch)
+	}
+	return nil
+}
+
+// Stop stops starting new runs, drops queued ones and waits for the runs in
+// progress to complete. If ctx is done first, the runs in progress are cancelled
+// and Stop returns ctx.Err() without waiting for them further.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	started := s.started
+	s.mu.Unlock()
+	if !started {
+		return nil
+	}
+
+	s.stopLoops()
+	s.loops.Wait()
+
+	drained := make(chan struct{})
+	go func() {
+		s.runs.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		s.cancelRuns()
+		return nil
+	case <-ctx.Done():
+		s.cancelRuns()
+		return ctx.Err()
+	}
+}
+
+// scheduleState tracks the runs of a schedule.
+type scheduleState struct {
+	mu      sync.Mutex
+	running int
+	queued  []time.Time // Scheduled times of the queued runs
+}
+
+// loop triggers the runs of sch until ctx is done or its trigger has no next time.
+func (s *Scheduler) loop(ctx, runCtx context.Context, sch *Schedule) {
+	defer s.loops.Done()
+
+	clock := s.clock()
+	state := &scheduleState{}
+	last := clock.Now()
+	trigger := sch.trigger(last)
+	if !sch.LastRun.IsZero() {
+		s.fire(ctx, runCtx, sch, state, trigger, sch.LastRun, last, false)
+	}
+
+	for {
+		next := trigger.Next(last)
+		if next.IsZero() {
+			return
+		}
+		wait := next.Sub(clock.Now())
+		if sch.Jitter > 0 {
+			wait += rand.N(sch.Jitter)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-clock.After(wait):
+		}
+
+		now := clock.Now()
+		s.fire(ctx, runCtx, sch, state, trigger, last, now, true)
+		last = now
+	}
+}
+
+// fire triggers the runs of sch due after from up to to. If current is set, the
+// latest of them runs regardless of the catch-up policy and the earlier ones
+// were missed; otherwise they were all missed.
+func (s *Scheduler) fire(ctx, runCtx context.Context, sch *Schedule, state *scheduleState, trigger Trigger, from, to time.Time, current bool) {
+	var latest time.Time
+	missed := 0
+	for t := trigger.Next(from); !t.IsZero() && !t.After(to); t = trigger.Next(t) {
+		if !latest.IsZero() {
+			missed++
+			if sch.CatchUp == CatchUpAll {
+				s.trigger(ctx, runCtx, sch, state, latest)
+			}
+		}
+		latest = t
+	}
+	if latest.IsZero() {
+		return
+	}
+	if !current {
+		missed++
+	}
+
+	switch {
+	case current, sch.CatchUp == CatchUpAll, sch.CatchUp == CatchUpOnce:
+		s.trigger(ctx, runCtx, sch, state, latest)
+	}
+	if missed > 0 && sch.CatchUp != CatchUpAll {
+		logMessage(ctx, s.Logger, LevelWarn, "scheduled runs missed",
+			slog.String("schedule", sch.Name), slog.Int("missed", missed), slog.Time("since", from))
+	}
+}
+
+// trigger starts, queues or skips a run of sch scheduled at the given time,
+// according to its overlap policy.
+func (s *Scheduler) trigger(ctx, runCtx context.Context, sch *Schedule, state *scheduleState, scheduled time.Time) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	switch {
+	case state.running == 0,
+		sch.Overlap == AllowOverlap && (sch.MaxConcurrency <= 0 || state.running < sch.MaxConcurrency):
+		state.running++
+		s.runs.Add(1)
+		go s.run(ctx, runCtx, sch, state, scheduled)
+	case sch.Overlap == QueueOverlap:
+		state.queued = append(state.queued, scheduled)
+	default:
+		logMessage(ctx, s.Logger, LevelWarn, "scheduled run skipped, previous run in progress",
+			slog.String("schedule", sch.Name), slog.Time("scheduled", scheduled), slog.Int("running", state.running))
+	}
+}
+
+// run runs the workflow of sch, then the runs queued meanwhile, until the
+// scheduler stops.
+func (s *Scheduler) run(ctx, runCtx context.Context, sch *Schedule, state *scheduleState, scheduled time.Time) {
+	defer s.runs.Done()
+
+	for {
+		report, err := sch.Workflow.Run(context.WithValue(runCtx, scheduledKey{}, scheduled), sch.Input)
+		if err != nil {
+			fields := []Field{slog.String("schedule", sch.Name), slog.Any("error", err)}
+			if report != nil {
+				fields = append(fields, slog.String(FieldRunID, report.RunID))
+			}
+			logMessage(runCtx, s.Logger, LevelError, "scheduled run failed", fields...)
+		}
+		if sch.OnComplete != nil {
+			sch.OnComplete(report, err)
+		}
+
+		state.mu.Lock()
+		if len(state.queued) > 0 && ctx.Err() == nil {
+			scheduled, state.queued = state.queued[0], state.queued[1:]
+			state.mu.Unlock()
+			continue
+		}
+		state.queued = nil
+		state.running--
+		state.mu.Unlock()
+		return
+	}
+}
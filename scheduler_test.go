@@ -0,0 +1,390 @@
+package taskflow_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/josuedeavila/taskflow"
+)
+
+// newBlockingWorkflow returns a workflow whose runs block until release is
+// closed, sending to the returned channel as each run starts and tracking the
+// peak number of runs in progress in *peak.
+func newBlockingWorkflow(t *testing.T, release <-chan struct{}, peak *atomic.Int32) (*taskflow.Workflow, <-chan struct{}) {
+	t.Helper()
+
+	started := make(chan struct{}, 100)
+	var running atomic.Int32
+	task := taskflow.NewTask("block", func(ctx context.Context, _ any) (any, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		started <- struct{}{}
+		select {
+		case <-release:
+			return nil, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}).WithLogger(taskflow.NoOpLogger{})
+
+	wf, err := taskflow.NewWorkflow(task)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return wf, started
+}
+
+func TestSchedulerRunsOnInterval(t *testing.T) {
+	var runs atomic.Int32
+	task := taskflow.NewTask("count", func(ctx context.Context, input int) (int, error) {
+		runs.Add(1)
+		return input, nil
+	}).WithLogger(taskflow.NoOpLogger{})
+	wf, err := taskflow.NewWorkflow(task)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	clock := taskflow.NewManualClock(time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC))
+	results := make(chan any, 10)
+	scheduler := startScheduler(t, clock, taskflow.Every("count", 10*time.Millisecond, wf).WithInput(7).
+		WithOnComplete(func(report *taskflow.RunReport, err error) {
+			rec, _ := report.Task("count")
+			results <- rec.Result
+		}))
+
+	for range 3 {
+		clock.Advance(10 * time.Millisecond)
+		if result := <-results; result != 7 {
+			t.Errorf("Expected result 7, got %v", result)
+		}
+		clock.BlockUntil(1)
+	}
+	if err := scheduler.Stop(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	clock.Advance(time.Hour)
+	if n := runs.Load(); n != 3 {
+		t.Errorf("Expected 3 runs, got %d", n)
+	}
+}
+
+func TestSchedulerOverlap(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     taskflow.OverlapPolicy
+		max        int
+		expectPeak int32
+		expectRuns int
+	}{
+		{"skip", taskflow.SkipOverlap, 0, 1, 1},
+		{"queue", taskflow.QueueOverlap, 0, 1, 4},
+		{"allow", taskflow.AllowOverlap, 3, 3, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var peak atomic.Int32
+			release := make(chan struct{})
+			wf, started := newBlockingWorkflow(t, release, &peak)
+
+			clock := taskflow.NewManualClock(time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC))
+			completed := make(chan error, 10)
+			scheduler := startScheduler(t, clock, taskflow.Every(tt.name, 5*time.Millisecond, wf).
+				WithOverlap(tt.policy).WithMaxConcurrency(tt.max).
+				WithOnComplete(func(report *taskflow.RunReport, err error) { completed <- err }))
+
+			// Ticks keep firing while the first runs are blocked
+			for range 4 {
+				clock.Advance(5 * time.Millisecond)
+				clock.BlockUntil(1)
+			}
+			for range tt.expectPeak {
+				<-started
+			}
+			if p := peak.Load(); p != tt.expectPeak {
+				t.Errorf("Expected %d runs at once, got %d", tt.expectPeak, p)
+			}
+
+			// Queued runs start once the blocked run completes
+			close(release)
+			for range tt.expectRuns {
+				if err := <-completed; err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			}
+			if err := scheduler.Stop(context.Background()); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if runs := int(tt.expectPeak) + len(started); runs != tt.expectRuns {
+				t.Errorf("Expected %d runs, got %d", tt.expectRuns, runs)
+			}
+		})
+	}
+}
+
+func TestSchedulerStopDrains(t *testing.T) {
+	var peak atomic.Int32
+	release := make(chan struct{})
+	wf, started := newBlockingWorkflow(t, release, &peak)
+
+	var completed atomic.Int32
+	clock := taskflow.NewManualClock(time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC))
+	scheduler := startScheduler(t, clock, taskflow.Every("drain", 5*time.Millisecond, wf).
+		WithOnComplete(func(report *taskflow.RunReport, err error) {
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			completed.Add(1)
+		}))
+	clock.Advance(5 * time.Millisecond)
+	<-started
+
+	time.AfterFunc(20*time.Millisecond, func() { close(release) })
+	if err := scheduler.Stop(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if completed.Load() != 1 {
+		t.Errorf("Expected Stop to wait for the run in progress, got %d completed runs", completed.Load())
+	}
+}
+
+func TestSchedulerStopTimeout(t *testing.T) {
+	var peak atomic.Int32
+	wf, started := newBlockingWorkflow(t, make(chan struct{}), &peak)
+
+	runErr := make(chan error, 1)
+	clock := taskflow.NewManualClock(time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC))
+	scheduler := startScheduler(t, clock, taskflow.Every("stuck", 5*time.Millisecond, wf).
+		WithOnComplete(func(report *taskflow.RunReport, err error) { runErr <- err }))
+	clock.Advance(5 * time.Millisecond)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := scheduler.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+
+	// The run in progress is cancelled
+	select {
+	case err := <-runErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected the run in progress to be cancelled")
+	}
+}
+
+func TestSchedulerStartErrors(t *testing.T) {
+	wf := newDoublingWorkflow(t)
+
+	scheduler := taskflow.NewScheduler()
+	scheduler.Add(taskflow.Every("invalid", 0, wf), taskflow.Every("missing", time.Second, nil))
+	if err := scheduler.Start(context.Background()); err == nil {
+		t.Error("Expected invalid schedules to fail")
+	}
+
+	scheduler = taskflow.NewScheduler()
+	scheduler.Add(taskflow.Every("valid", time.Hour, wf))
+	if err := scheduler.Start(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer scheduler.Stop(context.Background())
+	if err := scheduler.Start(context.Background()); err == nil {
+		t.Error("Expected second Start to fail")
+	}
+}
+
+// newRecordingWorkflow returns a workflow sending the scheduled time of each
+// run to the returned channel.
+func newRecordingWorkflow(t *testing.T) (*taskflow.Workflow, <-chan time.Time) {
+	t.Helper()
+
+	scheduled := make(chan time.Time, 100)
+	task := taskflow.NewTask("record", func(ctx context.Context, _ any) (any, error) {
+		at, ok := taskflow.ScheduledTime(ctx)
+		if !ok {
+			t.Error("Expected a scheduled time")
+		}
+		scheduled <- at
+		return nil, nil
+	}).WithLogger(taskflow.NoOpLogger{})
+
+	wf, err := taskflow.NewWorkflow(task)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return wf, scheduled
+}
+
+// startScheduler starts a scheduler with the schedule on clock and waits until
+// it waits for the next run.
+func startScheduler(t *testing.T, clock *taskflow.ManualClock, schedule *taskflow.Schedule) *taskflow.Scheduler {
+	t.Helper()
+
+	scheduler := taskflow.NewScheduler().WithLogger(taskflow.NoOpLogger{}).WithClock(clock)
+	scheduler.Add(schedule)
+	if err := scheduler.Start(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	clock.BlockUntil(1)
+	return scheduler
+}
+
+// drain stops the scheduler and returns the scheduled times of its runs.
+func drain(t *testing.T, scheduler *taskflow.Scheduler, scheduled <-chan time.Time) []time.Time {
+	t.Helper()
+
+	if err := scheduler.Stop(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var times []time.Time
+	for {
+		select {
+		case at := <-scheduled:
+			times = append(times, at)
+		default:
+			slices.SortFunc(times, time.Time.Compare)
+			return times
+		}
+	}
+}
+
+func TestSchedulerCron(t *testing.T) {
+	start := time.Date(2026, 3, 10, 10, 30, 0, 0, time.UTC)
+	clock := taskflow.NewManualClock(start)
+	wf, scheduled := newRecordingWorkflow(t)
+
+	cron, err := taskflow.ParseCron("0 * * * *", time.UTC)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// The task reports before its run completes, which may still be in progress at the next time
+	scheduler := startScheduler(t, clock, taskflow.NewSchedule("hourly", cron, wf).
+		WithOverlap(taskflow.AllowOverlap))
+
+	for _, expected := range []time.Time{
+		time.Date(2026, 3, 10, 11, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC),
+	} {
+		clock.Advance(expected.Sub(clock.Now()))
+		if at := <-scheduled; !at.Equal(expected) {
+			t.Errorf("Expected run scheduled at %v, got %v", expected, at)
+		}
+		clock.BlockUntil(1)
+	}
+
+	if times := drain(t, scheduler, scheduled); len(times) != 0 {
+		t.Errorf("Expected no other runs, got %v", times)
+	}
+}
+
+func TestSchedulerCatchUp(t *testing.T) {
+	start := time.Date(2026, 3, 10, 10, 30, 0, 0, time.UTC)
+	lastRun := start.Add(-3 * time.Hour)
+	missed := []time.Time{
+		time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name     string
+		policy   taskflow.CatchUpPolicy
+		expected []time.Time
+	}{
+		{"skip", taskflow.CatchUpSkip, nil},
+		{"once", taskflow.CatchUpOnce, missed[2:]},
+		{"all", taskflow.CatchUpAll, missed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := taskflow.NewManualClock(start)
+			wf, scheduled := newRecordingWorkflow(t)
+			cron, err := taskflow.ParseCron("0 * * * *", time.UTC)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			scheduler := startScheduler(t, clock, taskflow.NewSchedule("hourly", cron, wf).
+				WithOverlap(taskflow.AllowOverlap).
+				WithCatchUp(tt.policy, lastRun))
+
+			times := drain(t, scheduler, scheduled)
+			if !slices.EqualFunc(times, tt.expected, time.Time.Equal) {
+				t.Errorf("Expected runs scheduled at %v, got %v", tt.expected, times)
+			}
+		})
+	}
+}
+
+func TestSchedulerCatchUpInterval(t *testing.T) {
+	start := time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		policy   taskflow.CatchUpPolicy
+		expected int
+	}{
+		{"skip", taskflow.CatchUpSkip, 1},
+		{"all", taskflow.CatchUpAll, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := taskflow.NewManualClock(start)
+			wf, scheduled := newRecordingWorkflow(t)
+			scheduler := startScheduler(t, clock, taskflow.Every("minutely", time.Minute, wf).
+				WithOverlap(taskflow.AllowOverlap).
+				WithCatchUp(tt.policy, time.Time{}))
+
+			// The process is suspended past five runs
+			clock.Advance(5*time.Minute + 30*time.Second)
+			clock.BlockUntil(1)
+
+			times := drain(t, scheduler, scheduled)
+			if len(times) != tt.expected {
+				t.Fatalf("Expected %d runs, got %v", tt.expected, times)
+			}
+			if latest := start.Add(5 * time.Minute); !times[len(times)-1].Equal(latest) {
+				t.Errorf("Expected the latest run scheduled at %v, got %v", latest, times[len(times)-1])
+			}
+		})
+	}
+}
+
+func TestSchedulerJitter(t *testing.T) {
+	start := time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)
+	clock := taskflow.NewManualClock(start)
+	wf, scheduled := newRecordingWorkflow(t)
+	scheduler := startScheduler(t, clock, taskflow.Every("jittered", time.Minute, wf).
+		WithJitter(30*time.Second))
+
+	clock.Advance(59 * time.Second)
+	select {
+	case at := <-scheduled:
+		t.Fatalf("Expected no run before the scheduled time, got one scheduled at %v", at)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// The run starts within the jitter, but keeps its scheduled time
+	clock.Advance(31 * time.Second)
+	if at := <-scheduled; !at.Equal(start.Add(time.Minute)) {
+		t.Errorf("Expected run scheduled at %v, got %v", start.Add(time.Minute), at)
+	}
+	drain(t, scheduler, scheduled)
+}
@@ -0,0 +1,87 @@
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// migrations are applied in order; each one runs once per database. Never edit
+// a released migration, append a new one instead.
+var migrations = []string{
+	// 1: runs, task outcomes and attempts
+	`CREATE TABLE runs (
+		id          TEXT PRIMARY KEY,
+		workflow    TEXT NOT NULL,
+		status      TEXT NOT NULL,
+		started_at  INTEGER NOT NULL,
+		finished_at INTEGER,
+		error       TEXT
+	);
+	CREATE INDEX runs_workflow ON runs (workflow, started_at);
+
+	CREATE TABLE tasks (
+		run_id      TEXT NOT NULL REFERENCES runs (id) ON DELETE CASCADE,
+		task        TEXT NOT NULL,
+		status      TEXT NOT NULL,
+		started_at  INTEGER,
+		finished_at INTEGER,
+		attempts    INTEGER NOT NULL DEFAULT 0,
+		input       TEXT,
+		result      TEXT,
+		error       TEXT,
+		PRIMARY KEY (run_id, task)
+	);
+
+	CREATE TABLE attempts (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		run_id      TEXT NOT NULL REFERENCES runs (id) ON DELETE CASCADE,
+		task        TEXT NOT NULL,
+		attempt     INTEGER NOT NULL,
+		started_at  INTEGER NOT NULL,
+		finished_at INTEGER NOT NULL,
+		error       TEXT
+	);
+	CREATE INDEX attempts_task ON attempts (run_id, task);`,
+}
+
+// migrate brings the schema of db up to date. Each migration runs in its own
+// immediate transaction, so concurrent processes apply it only once.
+func migrate(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY)`); err != nil {
+		return fmt.Errorf("sqlitestore: create schema_migrations: %w", err)
+	}
+
+	for i, migration := range migrations {
+		version := i + 1
+		if err := apply(ctx, db, version, migration); err != nil {
+			return fmt.Errorf("sqlitestore: migration %d: %w", version, err)
+		}
+	}
+	return nil
+}
+
+// apply runs the migration unless the database already has it.
+func apply(ctx context.Context, db *sql.DB, version int, migration string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var applied int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations WHERE version = ?`, version).Scan(&applied); err != nil {
+		return err
+	}
+	if applied > 0 {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, migration); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES (?)`, version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,369 @@
+// Package sqlitestore is a durable taskflow.StateStore backed by a local SQLite
+// database file. Besides the state needed to resume runs, it records the history
+// of every run: the input, output and error of each task and each attempt, so
+// that several processes on a host can share it and past executions of a
+// workflow can be queried.
+//
+//	db, err := sqlitestore.Open("taskflow.db")
+//	runner := taskflow.NewRunner().WithStateStore(db.Workflow("etl"))
+//	runs, err := db.Runs(ctx, sqlitestore.RunQuery{Workflow: "etl", Limit: 10})
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/josuedeavila/taskflow"
+	_ "modernc.org/sqlite" // Registers the "sqlite" driver
+)
+
+// Run statuses.
+const (
+	RunRunning   = "running"
+	RunSucceeded = "succeeded"
+	RunFailed    = "failed"
+)
+
+// statusRunning is the status of a task that started and hasn't completed.
+const statusRunning = "running"
+
+// DB is a SQLite database holding the history of taskflow runs.
+type DB struct {
+	db *sql.DB
+
+	// OnError, if set, is called when recording an event fails. Observers
+	// can't return errors, so they are dropped otherwise.
+	OnError func(err error)
+}
+
+// Open opens the database file at path, creating it if needed, and applies
+// any pending schema migration.
+func Open(path string) (*DB, error) {
+	dsn := url.URL{
+		Scheme:   "file",
+		Opaque:   (&url.URL{Path: path}).EscapedPath(),
+		RawQuery: "_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)&_txlock=immediate",
+	}
+	db, err := sql.Open("sqlite", dsn.String())
+	if err != nil {
+		return nil, err
+	}
+	if err := migrate(context.Background(), db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &DB{db: db}, nil
+}
+
+// Close closes the database.
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// Workflow returns a store recording runs under the given workflow name.
+func (d *DB) Workflow(name string) *Store {
+	return &Store{db: d, workflow: name}
+}
+
+// Store is a taskflow.StateStore and taskflow.Observer recording the runs of a
+// workflow. A Runner using it as its state store also notifies it of events,
+// which records task inputs, attempts and the outcome of runs.
+type Store struct {
+	db       *DB
+	workflow string
+}
+
+// CreateRun implements taskflow.StateStore. Creating a run that exists, as
+// Workflow.Resume does, marks it as running again.
+func (s *Store) CreateRun(ctx context.Context, runID string) error {
+	_, err := s.db.db.ExecContext(ctx, `
+		INSERT INTO runs (id, workflow, status, started_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET status = excluded.status, finished_at = NULL, error = NULL`,
+		runID, s.workflow, RunRunning, time.Now().UnixNano())
+	return err
+}
+
+// SaveTask implements taskflow.StateStore.
+func (s *Store) SaveTask(ctx context.Context, runID string, state taskflow.TaskState) error {
+	_, err := s.db.db.ExecContext(ctx, `
+		INSERT INTO tasks (run_id, task, status, started_at, finished_at, attempts, result, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (run_id, task) DO UPDATE SET
+			status = excluded.status,
+			started_at = excluded.started_at,
+			finished_at = excluded.finished_at,
+			attempts = excluded.attempts,
+			result = excluded.result,
+			error = excluded.error`,
+		runID, state.Task, string(state.Status), unixNano(state.Start), unixNano(state.End),
+		state.Attempts, nullJSON(state.Result), nullString(state.Error))
+	return err
+}
+
+// LoadTasks implements taskflow.StateStore. Tasks that started but never
+// completed are left out.
+func (s *Store) LoadTasks(ctx context.Context, runID string) ([]taskflow.TaskState, error) {
+	var exists int
+	if err := s.db.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM runs WHERE id = ?`, runID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if exists == 0 {
+		return nil, fmt.Errorf("%w: %s", taskflow.ErrRunNotFound, runID)
+	}
+
+	tasks, err := s.db.Tasks(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	var states []taskflow.TaskState
+	for _, t := range tasks {
+		if t.Status == statusRunning {
+			continue
+		}
+		states = append(states, taskflow.TaskState{
+			Task:     t.Name,
+			Status:   taskflow.TaskStatus(t.Status),
+			Start:    t.Start,
+			End:      t.End,
+			Attempts: t.Attempts,
+			Result:   t.Result,
+			Error:    t.Error,
+		})
+	}
+	return states, nil
+}
+
+// Observe implements taskflow.Observer, recording task inputs, attempts and
+// the outcome of runs.
+func (s *Store) Observe(ctx context.Context, e taskflow.Event) {
+	// Record the history of cancelled runs too.
+	ctx = context.WithoutCancel(ctx)
+
+	var err error
+	switch e.Type {
+	case taskflow.EventTaskStarted:
+		input, _ := json.Marshal(e.Input) // Inputs that can't be encoded are left out
+		_, err = s.db.db.ExecContext(ctx, `
+			INSERT INTO tasks (run_id, task, status, started_at, input) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (run_id, task) DO UPDATE SET
+				status = excluded.status,
+				started_at = excluded.started_at,
+				input = excluded.input`,
+			e.RunID, e.Task, statusRunning, e.Time.UnixNano(), nullJSON(input))
+	case taskflow.EventTaskSkipped:
+		// Tasks skipped without running aren't saved as a state
+		_, err = s.db.db.ExecContext(ctx, `
+			INSERT INTO tasks (run_id, task, status) VALUES (?, ?, ?)
+			ON CONFLICT (run_id, task) DO NOTHING`,
+			e.RunID, e.Task, string(taskflow.StatusSkipped))
+	case taskflow.EventAttemptFinished:
+		_, err = s.db.db.ExecContext(ctx, `
+			INSERT INTO attempts (run_id, task, attempt, started_at, finished_at, error)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			e.RunID, e.Task, e.Attempt, e.Start.UnixNano(), e.Time.UnixNano(), errorString(e.Err))
+	case taskflow.EventRunFinished:
+		status := RunSucceeded
+		if e.Err != nil {
+			status = RunFailed
+		}
+		_, err = s.db.db.ExecContext(ctx, `UPDATE runs SET status = ?, finished_at = ?, error = ? WHERE id = ?`,
+			status, e.Time.UnixNano(), errorString(e.Err), e.RunID)
+	}
+
+	if err != nil && s.db.OnError != nil {
+		s.db.OnError(fmt.Errorf("sqlitestore: record %s of run %s: %w", e.Type, e.RunID, err))
+	}
+}
+
+// Run is a recorded run.
+type Run struct {
+	ID       string
+	Workflow string
+	Status   string // RunRunning, RunSucceeded or RunFailed
+	Start    time.Time
+	End      time.Time // Zero while running
+	Error    string
+}
+
+// Task is the recorded execution of a task in a run.
+type Task struct {
+	RunID    string
+	Name     string
+	Status   string // A taskflow.TaskStatus, or "running"
+	Start    time.Time
+	End      time.Time
+	Attempts int
+	Input    json.RawMessage // JSON encoding of the input, if it could be encoded
+	Result   json.RawMessage // JSON encoding of the result of a succeeded task
+	Error    string
+}
+
+// Attempt is a recorded call to a task function.
+type Attempt struct {
+	RunID   string
+	Task    string
+	Attempt int // Attempt number within the process that ran it, starting at 1
+	Start   time.Time
+	End     time.Time
+	Error   string
+}
+
+// RunQuery selects runs. Zero fields match every run.
+type RunQuery struct {
+	Workflow string
+	Status   string
+	Since    time.Time // Runs started at or after Since
+	Limit    int       // Maximum number of runs returned
+}
+
+// Runs returns the runs matching q, most recent first.
+func (d *DB) Runs(ctx context.Context, q RunQuery) ([]Run, error) {
+	query := `SELECT id, workflow, status, started_at, finished_at, error FROM runs WHERE 1 = 1`
+	var args []any
+	if q.Workflow != "" {
+		query += ` AND workflow = ?`
+		args = append(args, q.Workflow)
+	}
+	if q.Status != "" {
+		query += ` AND status = ?`
+		args = append(args, q.Status)
+	}
+	if !q.Since.IsZero() {
+		query += ` AND started_at >= ?`
+		args = append(args, q.Since.UnixNano())
+	}
+	query += ` ORDER BY started_at DESC, id`
+	if q.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, q.Limit)
+	}
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []Run
+	for rows.Next() {
+		var r Run
+		var start int64
+		var end sql.NullInt64
+		var errMsg sql.NullString
+		if err := rows.Scan(&r.ID, &r.Workflow, &r.Status, &start, &end, &errMsg); err != nil {
+			return nil, err
+		}
+		r.Start, r.End, r.Error = time.Unix(0, start), fromUnixNano(end), errMsg.String
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
+
+// Run returns the run with the given ID, or an error wrapping taskflow.ErrRunNotFound.
+func (d *DB) Run(ctx context.Context, runID string) (Run, error) {
+	var r Run
+	var start int64
+	var end sql.NullInt64
+	var errMsg sql.NullString
+	err := d.db.QueryRowContext(ctx, `SELECT id, workflow, status, started_at, finished_at, error FROM runs WHERE id = ?`, runID).
+		Scan(&r.ID, &r.Workflow, &r.Status, &start, &end, &errMsg)
+	if errors.Is(err, sql.ErrNoRows) {
+		return r, fmt.Errorf("%w: %s", taskflow.ErrRunNotFound, runID)
+	}
+	if err != nil {
+		return r, err
+	}
+	r.Start, r.End, r.Error = time.Unix(0, start), fromUnixNano(end), errMsg.String
+	return r, nil
+}
+
+// Tasks returns the tasks recorded for a run, in the order they started.
+func (d *DB) Tasks(ctx context.Context, runID string) ([]Task, error) {
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT task, status, started_at, finished_at, attempts, input, result, error
+		FROM tasks WHERE run_id = ? ORDER BY started_at IS NULL, started_at, task`, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []Task
+	for rows.Next() {
+		t := Task{RunID: runID}
+		var start, end sql.NullInt64
+		var input, result, errMsg sql.NullString
+		if err := rows.Scan(&t.Name, &t.Status, &start, &end, &t.Attempts, &input, &result, &errMsg); err != nil {
+			return nil, err
+		}
+		t.Start, t.End, t.Error = fromUnixNano(start), fromUnixNano(end), errMsg.String
+		if input.Valid {
+			t.Input = json.RawMessage(input.String)
+		}
+		if result.Valid {
+			t.Result = json.RawMessage(result.String)
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, rows.Err()
+}
+
+// Attempts returns the attempts recorded for a run, in the order they finished.
+func (d *DB) Attempts(ctx context.Context, runID string) ([]Attempt, error) {
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT task, attempt, started_at, finished_at, error
+		FROM attempts WHERE run_id = ? ORDER BY id`, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attempts []Attempt
+	for rows.Next() {
+		a := Attempt{RunID: runID}
+		var start, end int64
+		var errMsg sql.NullString
+		if err := rows.Scan(&a.Task, &a.Attempt, &start, &end, &errMsg); err != nil {
+			return nil, err
+		}
+		a.Start, a.End, a.Error = time.Unix(0, start), time.Unix(0, end), errMsg.String
+		attempts = append(attempts, a)
+	}
+	return attempts, rows.Err()
+}
+
+// unixNano returns t in nanoseconds, or NULL for the zero time.
+func unixNano(t time.Time) sql.NullInt64 {
+	if t.IsZero() {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: t.UnixNano(), Valid: true}
+}
+
+// fromUnixNano is the inverse of unixNano.
+func fromUnixNano(n sql.NullInt64) time.Time {
+	if !n.Valid {
+		return time.Time{}
+	}
+	return time.Unix(0, n.Int64)
+}
+
+func nullJSON(data []byte) sql.NullString {
+	return sql.NullString{String: string(data), Valid: len(data) > 0}
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func errorString(err error) sql.NullString {
+	if err == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: err.Error(), Valid: true}
+}
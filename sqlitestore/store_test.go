@@ -0,0 +1,235 @@
+package sqlitestore_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/josuedeavila/taskflow"
+	"github.com/josuedeavila/taskflow/sqlitestore"
+)
+
+func openDB(t *testing.T, path string) *sqlitestore.DB {
+	t.Helper()
+	db, err := sqlitestore.Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	db.OnError = func(err error) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	return db
+}
+
+// newRunner returns a runner adding 1 to its input and then doubling it, where
+// doubling fails while *fail is set. Calls to the first task are counted in *adds.
+func newRunner(store taskflow.StateStore, adds *int, fail *bool) *taskflow.Runner {
+	add := taskflow.NewTask("add", func(ctx context.Context, _ any) (int, error) {
+		*adds++
+		return 21, nil
+	}).WithLogger(taskflow.NoOpLogger{})
+
+	double := taskflow.NewTask("double", func(ctx context.Context, n int) (int, error) {
+		if *fail {
+			return 0, errors.New("double failed")
+		}
+		return n * 2, nil
+	}).WithRetry(taskflow.RetryPolicy{
+		MaxRetries: 1,
+		Strategy:   taskflow.ConstantBackoff,
+		BaseDelay:  time.Millisecond,
+	}).WithLogger(taskflow.NoOpLogger{}).After(add)
+
+	runner := taskflow.NewRunner().WithStateStore(store)
+	runner.Add(double)
+	return runner
+}
+
+func TestStoreResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "taskflow.db")
+	ctx := context.Background()
+
+	adds, fail := 0, true
+	report, err := newRunner(openDB(t, path).Workflow("math"), &adds, &fail).Run(ctx)
+	if err == nil {
+		t.Fatal("Expected first run to fail")
+	}
+
+	// Another process sharing the database file resumes the run
+	db := openDB(t, path)
+	fail = false
+	var status string
+	runner := newRunner(db.Workflow("math"), &adds, &fail).WithObserver(taskflow.ObserverFunc(func(ctx context.Context, e taskflow.Event) {
+		if e.Type == taskflow.EventRunStarted {
+			run, _ := db.Run(ctx, e.RunID)
+			status = run.Status
+		}
+	}))
+	resumed, err := runner.Resume(ctx, report.RunID)
+	if err != nil {
+		t.Fatalf("Expected resumed run to succeed, got %v", err)
+	}
+	if status != sqlitestore.RunRunning {
+		t.Errorf("Expected resumed run to be running, got %q", status)
+	}
+	if adds != 1 {
+		t.Errorf("Expected add to run once, ran %d times", adds)
+	}
+	if rec, _ := resumed.Task("double"); rec.Result != 42 {
+		t.Errorf("Expected 42, got %v", rec.Result)
+	}
+
+	run, err := db.Run(ctx, report.RunID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if run.Workflow != "math" || run.Status != sqlitestore.RunSucceeded || run.End.IsZero() {
+		t.Errorf("Expected finished math run, got %+v", run)
+	}
+
+	tasks, err := db.Tasks(ctx, report.RunID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("Expected 2 tasks, got %d", len(tasks))
+	}
+	double := tasks[1]
+	if double.Name != "double" || double.Status != string(taskflow.StatusSucceeded) {
+		t.Errorf("Expected double to succeed, got %+v", double)
+	}
+	if string(double.Input) != "21" || string(double.Result) != "42" {
+		t.Errorf("Expected input 21 and result 42, got %s and %s", double.Input, double.Result)
+	}
+
+	attempts, err := db.Attempts(ctx, report.RunID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// add once, double twice in the first run and once when resumed
+	if len(attempts) != 4 {
+		t.Fatalf("Expected 4 attempts, got %d", len(attempts))
+	}
+	failed := 0
+	for _, a := range attempts {
+		if a.Error == "double failed" {
+			failed++
+		}
+	}
+	if failed != 2 {
+		t.Errorf("Expected 2 failed attempts, got %d", failed)
+	}
+}
+
+func TestStoreRuns(t *testing.T) {
+	db := openDB(t, filepath.Join(t.TempDir(), "taskflow.db"))
+	ctx := context.Background()
+
+	adds, fail := 0, false
+	for range 3 {
+		if _, err := newRunner(db.Workflow("math"), &adds, &fail).Run(ctx); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	fail = true
+	_, _ = newRunner(db.Workflow("math"), &adds, &fail).Run(ctx)
+	_, _ = newRunner(db.Workflow("other"), &adds, &fail).Run(ctx)
+
+	tests := []struct {
+		name     string
+		query    sqlitestore.RunQuery
+		expected int
+	}{
+		{"all runs", sqlitestore.RunQuery{}, 5},
+		{"by workflow", sqlitestore.RunQuery{Workflow: "math"}, 4},
+		{"by status", sqlitestore.RunQuery{Workflow: "math", Status: sqlitestore.RunFailed}, 1},
+		{"limit", sqlitestore.RunQuery{Workflow: "math", Limit: 2}, 2},
+		{"since", sqlitestore.RunQuery{Since: time.Now().Add(time.Hour)}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs, err := db.Runs(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(runs) != tt.expected {
+				t.Errorf("Expected %d runs, got %d", tt.expected, len(runs))
+			}
+		})
+	}
+
+	runs, _ := db.Runs(ctx, sqlitestore.RunQuery{Workflow: "other"})
+	if len(runs) != 1 || runs[0].Error != "double failed" {
+		t.Errorf("Expected the failed run's error, got %+v", runs)
+	}
+}
+
+func TestStoreSkippedTasks(t *testing.T) {
+	db := openDB(t, filepath.Join(t.TempDir(), "taskflow.db"))
+	ctx := context.Background()
+
+	failing := taskflow.NewTask("failing", func(ctx context.Context, _ any) (any, error) {
+		return nil, errors.New("failure")
+	}).WithLogger(taskflow.NoOpLogger{})
+	dependent := taskflow.NewTask("dependent", func(ctx context.Context, _ any) (any, error) {
+		return nil, nil
+	}).WithLogger(taskflow.NoOpLogger{}).After(failing)
+
+	runner := taskflow.NewRunner().WithStateStore(db.Workflow("skips"))
+	runner.Add(dependent)
+	report, _ := runner.Run(ctx)
+
+	tasks, err := db.Tasks(ctx, report.RunID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	statuses := make(map[string]string)
+	for _, task := range tasks {
+		statuses[task.Name] = task.Status
+	}
+	if statuses["failing"] != "failed" || statuses["dependent"] != "skipped" {
+		t.Errorf("Expected failing to fail and dependent to be skipped, got %v", statuses)
+	}
+}
+
+func TestStoreRunNotFound(t *testing.T) {
+	db := openDB(t, filepath.Join(t.TempDir(), "taskflow.db"))
+
+	if _, err := db.Run(context.Background(), "unknown"); !errors.Is(err, taskflow.ErrRunNotFound) {
+		t.Errorf("Expected ErrRunNotFound, got %v", err)
+	}
+	if _, err := db.Workflow("math").LoadTasks(context.Background(), "unknown"); !errors.Is(err, taskflow.ErrRunNotFound) {
+		t.Errorf("Expected ErrRunNotFound, got %v", err)
+	}
+}
+
+func TestOpenMigratesOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "taskflow.db")
+
+	// Reopening an up to date database applies nothing
+	for range 2 {
+		db, err := sqlitestore.Open(path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		db.Close()
+	}
+}
+
+func TestOpenEscapesPath(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "runs?#%20")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	path := filepath.Join(dir, "taskflow.db")
+	openDB(t, path)
+
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected database at %s, got %v", path, err)
+	}
+}
@@ -0,0 +1,238 @@
+package taskflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrRunNotFound is returned by a StateStore asked about a run it doesn't know.
+var ErrRunNotFound = errors.New("taskflow: run not found")
+
+// TaskState is the persisted outcome of a task in a run.
+type TaskState struct {
+	Task     string          `json:"task"`
+	Status   TaskStatus      `json:"status"`
+	Start    time.Time       `json:"start"`
+	End      time.Time       `json:"end"`
+	Attempts int             `json:"attempts"`
+	Result   json.RawMessage `json:"result,omitempty"` // JSON encoding of the result of a succeeded task, if it has one
+	Error    string          `json:"error,omitempty"`
+}
+
+// StateStore persists the outcome of the tasks of a run, so that an interrupted
+// run can be resumed, see Workflow.Resume. Implementations must be safe for
+// concurrent use. A StateStore that also implements Observer is notified of
+// the events of the runs it persists.
+type StateStore interface {
+	// CreateRun records that a run with the given ID started, or that it was
+	// resumed, in which case the states saved for it must be kept.
+	CreateRun(ctx context.Context, runID string) error
+	// SaveTask persists the outcome of a task of the run, replacing any
+	// previous state of the same task.
+	SaveTask(ctx context.Context, runID string, state TaskState) error
+	// LoadTasks returns the states saved for the run, or ErrRunNotFound.
+	LoadTasks(ctx context.Context, runID string) ([]TaskState, error)
+}
+
+// restorer is implemented by executables that can decode a persisted result.
+type restorer interface {
+	restoreResult(data json.RawMessage) (any, error)
+}
+
+// newTaskState returns the state to persist for a task record. The result of
+// a succeeded task is left out if it can't be encoded, so that the task runs
+// again when the run is resumed.
+func newTaskState(rec TaskRecord) TaskState {
+	state := TaskState{
+		Task:     rec.Name,
+		Status:   rec.Status,
+		Start:    rec.Start,
+		End:      rec.End,
+		Attempts: rec.Attempts,
+	}
+	if rec.Err != nil {
+		state.Error = rec.Err.Error()
+	}
+	if rec.Status == StatusSucceeded {
+		if data, err := json.Marshal(rec.Result); err == nil {
+			state.Result = data
+		}
+	}
+	return state
+}
+
+// MemoryStore is a StateStore keeping the states in memory, which suits tests
+// and runs resumed within the same process.
+type MemoryStore struct {
+	mu   sync.Mutex
+	runs map[string][]TaskState
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{runs: make(map[string][]TaskState)}
+}
+
+// CreateRun implements StateStore.
+func (s *MemoryStore) CreateRun(ctx context.Context, runID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.runs[runID]; !ok {
+		s.runs[runID] = nil
+	}
+	return nil
+}
+
+// SaveTask implements StateStore.
+func (s *MemoryStore) SaveTask(ctx context.Context, runID string, state TaskState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	states, ok := s.runs[runID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrRunNotFound, runID)
+	}
+	s.runs[runID] = replaceState(states, state)
+	return nil
+}
+
+// LoadTasks implements StateStore.
+func (s *MemoryStore) LoadTasks(ctx context.Context, runID string) ([]TaskState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	states, ok := s.runs[runID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRunNotFound, runID)
+	}
+	return append([]TaskState(nil), states...), nil
+}
+
+// FileStore is a StateStore keeping each run in a JSON file named after the
+// run ID in a directory.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore returns a FileStore writing to dir, which is created if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// fileRun is the content of a FileStore file.
+type fileRun struct {
+	RunID string      `json:"run_id"`
+	Tasks []TaskState `json:"tasks"`
+}
+
+// CreateRun implements StateStore.
+func (s *FileStore) CreateRun(ctx context.Context, runID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.read(runID); !errors.Is(err, ErrRunNotFound) {
+		return err
+	}
+	return s.write(fileRun{RunID: runID, Tasks: []TaskState{}})
+}
+
+// SaveTask implements StateStore.
+func (s *FileStore) SaveTask(ctx context.Context, runID string, state TaskState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run, err := s.read(runID)
+	if err != nil {
+		return err
+	}
+	run.Tasks = replaceState(run.Tasks, state)
+	return s.write(run)
+}
+
+// LoadTasks implements StateStore.
+func (s *FileStore) LoadTasks(ctx context.Context, runID string) ([]TaskState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run, err := s.read(runID)
+	if err != nil {
+		return nil, err
+	}
+	return run.Tasks, nil
+}
+
+// path returns the file of the run. Run IDs are used as file names, so they
+// must not contain path separators.
+func (s *FileStore) path(runID string) (string, error) {
+	if runID == "" || runID != filepath.Base(runID) {
+		return "", fmt.Errorf("taskflow: invalid run ID %q", runID)
+	}
+	return filepath.Join(s.dir, runID+".json"), nil
+}
+
+func (s *FileStore) read(runID string) (fileRun, error) {
+	var run fileRun
+	path, err := s.path(runID)
+	if err != nil {
+		return run, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return run, fmt.Errorf("%w: %s", ErrRunNotFound, runID)
+	}
+	if err != nil {
+		return run, err
+	}
+	if err := json.Unmarshal(data, &run); err != nil {
+		return run, fmt.Errorf("taskflow: read run %s: %w", runID, err)
+	}
+	return run, nil
+}
+
+// write replaces the file of the run atomically, so that a crash never leaves
+// a partially written file behind.
+func (s *FileStore) write(run fileRun) error {
+	path, err := s.path(run.RunID)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, run.RunID+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// replaceState replaces the state of the same task in states, or appends it.
+func replaceState(states []TaskState, state TaskState) []TaskState {
+	for i, s := range states {
+		if s.Task == state.Task {
+			states[i] = state
+			return states
+		}
+	}
+	return append(states, state)
+}
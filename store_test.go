@@ -0,0 +1,311 @@
+package taskflow_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/josuedeavila/taskflow"
+)
+
+type user struct {
+	Name string
+	Age  int
+}
+
+// newCheckpointedRunner returns a runner with a fetch task followed by a process
+// task that fails while *fail is set. Calls to fetch are counted in *fetches.
+func newCheckpointedRunner(store taskflow.StateStore, fetches *int, fail *bool) *taskflow.Runner {
+	fetch := taskflow.NewTask("fetch", func(ctx context.Context, _ any) ([]user, error) {
+		*fetches++
+		return []user{{Name: "ana", Age: 30}, {Name: "bob", Age: 40}}, nil
+	}).WithLogger(taskflow.NoOpLogger{})
+
+	process := taskflow.NewTask("process", func(ctx context.Context, users []user) (int, error) {
+		if *fail {
+			return 0, errors.New("process crashed")
+		}
+		total := 0
+		for _, u := range users {
+			total += u.Age
+		}
+		return total, nil
+	}).WithLogger(taskflow.NoOpLogger{}).After(fetch)
+
+	runner := taskflow.NewRunner().WithStateStore(store)
+	runner.Add(process)
+	return runner
+}
+
+func TestRunnerResume(t *testing.T) {
+	stores := map[string]func(t *testing.T) taskflow.StateStore{
+		"memory": func(t *testing.T) taskflow.StateStore {
+			return taskflow.NewMemoryStore()
+		},
+		"file": func(t *testing.T) taskflow.StateStore {
+			store, err := taskflow.NewFileStore(t.TempDir())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			return store
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			fetches, fail := 0, true
+
+			report, err := newCheckpointedRunner(store, &fetches, &fail).Run(context.Background())
+			if err == nil {
+				t.Fatal("Expected first run to fail")
+			}
+
+			// Simulate a restart with new task definitions
+			fetches, fail = 0, false
+			resumed, err := newCheckpointedRunner(store, &fetches, &fail).Resume(context.Background(), report.RunID)
+			if err != nil {
+				t.Fatalf("Expected resumed run to succeed, got %v", err)
+			}
+
+			if fetches != 0 {
+				t.Errorf("Expected fetch not to run again, ran %d times", fetches)
+			}
+			if resumed.RunID != report.RunID {
+				t.Errorf("Expected run ID %s, got %s", report.RunID, resumed.RunID)
+			}
+
+			fetch, _ := resumed.Task("fetch")
+			if !fetch.Restored || fetch.Status != taskflow.StatusSucceeded {
+				t.Errorf("Expected fetch to be restored, got %+v", fetch)
+			}
+			process, _ := resumed.Task("process")
+			if process.Restored || process.Result != 70 {
+				t.Errorf("Expected process to run with the restored users, got %+v", process)
+			}
+
+			states, err := store.LoadTasks(context.Background(), report.RunID)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(states) != 2 {
+				t.Fatalf("Expected 2 task states, got %d", len(states))
+			}
+			for _, state := range states {
+				if state.Status != taskflow.StatusSucceeded {
+					t.Errorf("Expected %s to be stored as succeeded, got %s", state.Task, state.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestRunnerResumeCompletedRun(t *testing.T) {
+	store := taskflow.NewMemoryStore()
+	fetches, fail := 0, false
+
+	report, err := newCheckpointedRunner(store, &fetches, &fail).Run(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resumed, err := newCheckpointedRunner(store, &fetches, &fail).Resume(context.Background(), report.RunID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fetches != 1 {
+		t.Errorf("Expected fetch to run once, ran %d times", fetches)
+	}
+	for _, rec := range resumed.Tasks {
+		if !rec.Restored {
+			t.Errorf("Expected %s to be restored", rec.Name)
+		}
+	}
+}
+
+func TestRunnerResumeSequentialChain(t *testing.T) {
+	store := taskflow.NewMemoryStore()
+	calls, fail := 0, true
+	newRunner := func() *taskflow.Runner {
+		d1 := taskflow.NewTask("d1", func(ctx context.Context, _ any) (int, error) {
+			calls++
+			return 20, nil
+		}).WithLogger(taskflow.NoOpLogger{})
+		d2 := taskflow.NewTask("d2", func(ctx context.Context, n int) (int, error) {
+			calls++
+			return n + 1, nil
+		}).WithLogger(taskflow.NoOpLogger{})
+		chain := taskflow.NewTask("chain", func(ctx context.Context, n int) (int, error) {
+			return n * 2, nil
+		}).WithLogger(taskflow.NoOpLogger{}).WithDependencyMode(taskflow.SequentialDependencies).After(d1, d2)
+		last := taskflow.NewTask("last", func(ctx context.Context, n int) (int, error) {
+			if fail {
+				return 0, errors.New("last failed")
+			}
+			return n, nil
+		}).WithLogger(taskflow.NoOpLogger{}).After(chain)
+
+		runner := taskflow.NewRunner().WithStateStore(store)
+		runner.Add(last)
+		return runner
+	}
+
+	report, err := newRunner().Run(context.Background())
+	if err == nil {
+		t.Fatal("Expected first run to fail")
+	}
+
+	calls, fail = 0, false
+	resumed, err := newRunner().Resume(context.Background(), report.RunID)
+	if err != nil {
+		t.Fatalf("Expected resumed run to succeed, got %v", err)
+	}
+	if !resumed.Succeeded() {
+		t.Errorf("Expected resumed run to succeed, got %+v", resumed.Tasks)
+	}
+	if calls != 0 {
+		t.Errorf("Expected the chain not to run again, ran %d tasks", calls)
+	}
+	for _, name := range []string{"d1", "d2", "chain"} {
+		if rec, _ := resumed.Task(name); !rec.Restored || rec.Status != taskflow.StatusSucceeded {
+			t.Errorf("Expected %s to be restored, got %+v", name, rec)
+		}
+	}
+	if rec, _ := resumed.Task("last"); rec.Result != 42 {
+		t.Errorf("Expected 42, got %+v", rec)
+	}
+}
+
+// countingStore is a MemoryStore counting the runs created.
+type countingStore struct {
+	*taskflow.MemoryStore
+	created []string
+}
+
+func (s *countingStore) CreateRun(ctx context.Context, runID string) error {
+	s.created = append(s.created, runID)
+	return s.MemoryStore.CreateRun(ctx, runID)
+}
+
+func TestRunnerResumeCreatesRun(t *testing.T) {
+	store := &countingStore{MemoryStore: taskflow.NewMemoryStore()}
+	fetches, fail := 0, true
+
+	report, _ := newCheckpointedRunner(store, &fetches, &fail).Run(context.Background())
+	fail = false
+	if _, err := newCheckpointedRunner(store, &fetches, &fail).Resume(context.Background(), report.RunID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(store.created) != 2 || store.created[1] != report.RunID {
+		t.Errorf("Expected run %s to be created again on resume, got %v", report.RunID, store.created)
+	}
+}
+
+func TestRunnerResumeUnknownRun(t *testing.T) {
+	fetches, fail := 0, false
+	runner := newCheckpointedRunner(taskflow.NewMemoryStore(), &fetches, &fail)
+
+	_, err := runner.Resume(context.Background(), "unknown")
+	if !errors.Is(err, taskflow.ErrRunNotFound) {
+		t.Errorf("Expected ErrRunNotFound, got %v", err)
+	}
+	if fetches != 0 {
+		t.Errorf("Expected no task to run, fetch ran %d times", fetches)
+	}
+}
+
+func TestRunnerResumeWithoutStore(t *testing.T) {
+	runner := taskflow.NewRunner()
+	if _, err := runner.Resume(context.Background(), "run"); err == nil {
+		t.Error("Expected error, got nil")
+	}
+}
+
+func TestRunnerCheckpointUnencodableResult(t *testing.T) {
+	calls := 0
+	task := taskflow.NewTask("channel", func(ctx context.Context, _ any) (chan int, error) {
+		calls++
+		return make(chan int), nil
+	}).WithLogger(taskflow.NoOpLogger{})
+
+	runner := taskflow.NewRunner().WithStateStore(taskflow.NewMemoryStore())
+	runner.Add(task)
+
+	report, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rec, _ := report.Task("channel"); rec.Status != taskflow.StatusSucceeded {
+		t.Errorf("Expected task to succeed, got %s", rec.Status)
+	}
+
+	resumed, err := runner.Resume(context.Background(), report.RunID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rec, _ := resumed.Task("channel"); rec.Restored || calls != 2 {
+		t.Errorf("Expected task to run again, got %+v after %d calls", rec, calls)
+	}
+}
+
+func TestRunnerStoreRequiresNames(t *testing.T) {
+	one := taskflow.NewTask("", func(ctx context.Context, _ any) (int, error) { return 1, nil })
+	two := taskflow.NewTask("", func(ctx context.Context, _ any) (int, error) { return 2, nil })
+
+	runner := taskflow.NewRunner().WithStateStore(taskflow.NewMemoryStore())
+	runner.Add(one, two)
+
+	if _, err := runner.Run(context.Background()); err == nil || !strings.Contains(err.Error(), "requires named tasks") {
+		t.Errorf("Expected unnamed task error, got %v", err)
+	}
+	if _, err := runner.Resume(context.Background(), "run"); err == nil || !strings.Contains(err.Error(), "requires named tasks") {
+		t.Errorf("Expected unnamed task error, got %v", err)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := taskflow.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	if err := store.SaveTask(ctx, "run1", taskflow.TaskState{Task: "a"}); !errors.Is(err, taskflow.ErrRunNotFound) {
+		t.Errorf("Expected ErrRunNotFound before CreateRun, got %v", err)
+	}
+	if err := store.CreateRun(ctx, "../escape"); err == nil {
+		t.Error("Expected invalid run ID error, got nil")
+	}
+
+	if err := store.CreateRun(ctx, "run1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_ = store.SaveTask(ctx, "run1", taskflow.TaskState{Task: "a", Status: taskflow.StatusFailed, Error: "boom"})
+	_ = store.SaveTask(ctx, "run1", taskflow.TaskState{Task: "a", Status: taskflow.StatusSucceeded, Result: []byte(`"ok"`)})
+
+	// Creating an existing run keeps its states
+	if err := store.CreateRun(ctx, "run1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	states, err := store.LoadTasks(ctx, "run1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(states) != 1 || states[0].Status != taskflow.StatusSucceeded || string(states[0].Result) != `"ok"` {
+		t.Errorf("Expected the latest state of a, got %+v", states)
+	}
+
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 || files[0].Name() != "run1.json" {
+		t.Errorf("Expected a single run1.json file, got %v", files)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "run1.json")); err != nil {
+		t.Errorf("Expected run file, got %v", err)
+	}
+}
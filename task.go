@@ -2,8 +2,13 @@ package taskflow
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"reflect"
 	"sync"
+	"time"
 )
 
 // Executable defines the interface for a task that can be executed.
@@ -12,18 +17,63 @@ type Executable interface {
 	GetResult() any
 }
 
+// Node is implemented by executables that expose their name and dependencies,
+// which allows a Graph to validate them. Executables that don't implement it
+// are treated as unnamed tasks without dependencies.
+type Node interface {
+	Executable
+	GetName() string
+	GetDependencies() []Executable
+}
+
+// Tagged is implemented by executables that belong to groups, such as the
+// ones a Runner uses to limit concurrency.
+type Tagged interface {
+	GetTags() []string
+}
+
+// Typed is implemented by executables that know the types of their input and output.
+type Typed interface {
+	InputType() reflect.Type
+	OutputType() reflect.Type
+}
+
 // TaskFunc defines the signature for a function that can be executed as a task.
 type TaskFunc[In any, Out any] func(ctx context.Context, input In) (Out, error)
 
+// DependencyMode controls how a Task executes its dependencies.
+type DependencyMode int
+
+const (
+	// ParallelDependencies runs all dependencies concurrently, each one receiving
+	// the task's input. A single dependency's output is handed to the task as is;
+	// with several dependencies the task receives their outputs as a []any, in
+	// the order the dependencies were declared.
+	ParallelDependencies DependencyMode = iota
+	// SequentialDependencies runs dependencies one after another, feeding the
+	// output of each dependency into the next one and the last output into the task.
+	// The chain runs inside the task, so its dependencies don't count towards the
+	// MaxConcurrency and GroupLimits of a Runner, and they can't be shared with
+	// other tasks, see SharedChainError.
+	SequentialDependencies
+)
+
 // Task represents a unit of work that can be executed.
 type Task[In any, Out any] struct {
-	Name    string
-	Fn      TaskFunc[In, Out]
-	Depends []Executable // Dependencies that must be completed before this task can run
-	Result  Out
-	Err     error
-	once    sync.Once
-	Logger  Logger // Optional logger for task execution
+	Name           string
+	Fn             TaskFunc[In, Out]
+	Depends        []Executable   // Dependencies that must be completed before this task can run
+	DependencyMode DependencyMode // How dependencies are executed, ParallelDependencies by default
+	Tags           []string       // Groups the task belongs to, see Runner.WithGroupLimit
+	RetryPolicy    *RetryPolicy   // Optional policy to retry failed calls to Fn
+	Timeout        time.Duration  // Optional time limit for each call to Fn
+	Repanic        bool           // Let panics in Fn crash the program instead of returning a PanicError
+	Result         Out            // Result of the most recent execution
+	Err            error          // Error of the most recent execution
+	mu             sync.Mutex
+	Logger         Logger         // Optional logger for task execution
+	Observers      []Observer     // Notified at each lifecycle transition of the task
+	inputTypes     []reflect.Type // Per-dependency input types of join tasks
 }
 
 // NewTask creates a new Task with the given name and function.
@@ -37,6 +87,44 @@ func (t *Task[In, Out]) WithLogger(logger Logger) *Task[In, Out] {
 	return t
 }
 
+// WithObserver adds observers notified at each lifecycle transition of the task.
+func (t *Task[In, Out]) WithObserver(observers ...Observer) *Task[In, Out] {
+	t.Observers = append(t.Observers, observers...)
+	return t
+}
+
+// WithDependencyMode sets how the task executes its dependencies.
+func (t *Task[In, Out]) WithDependencyMode(mode DependencyMode) *Task[In, Out] {
+	t.DependencyMode = mode
+	return t
+}
+
+// WithTags adds the task to the given groups.
+func (t *Task[In, Out]) WithTags(tags ...string) *Task[In, Out] {
+	t.Tags = append(t.Tags, tags...)
+	return t
+}
+
+// WithRetry retries failed calls to the task function according to policy.
+func (t *Task[In, Out]) WithRetry(policy RetryPolicy) *Task[In, Out] {
+	t.RetryPolicy = &policy
+	return t
+}
+
+// WithTimeout limits each call to the task function, including retries, to d.
+func (t *Task[In, Out]) WithTimeout(d time.Duration) *Task[In, Out] {
+	t.Timeout = d
+	return t
+}
+
+// WithRepanic controls whether a panic in the task function crashes the program,
+// which keeps the original stack for debugging. By default, the panic is
+// recovered and returned as a *PanicError.
+func (t *Task[In, Out]) WithRepanic(repanic bool) *Task[In, Out] {
+	t.Repanic = repanic
+	return t
+}
+
 // After adds dependencies to the task.
 func (t *Task[In, Out]) After(tasks ...Executable) *Task[In, Out] {
 	t.Depends = append(t.Depends, tasks...)
@@ -44,42 +132,295 @@ func (t *Task[In, Out]) After(tasks ...Executable) *Task[In, Out] {
 }
 
 // Run executes the task and its dependencies.
+// Each call starts a new execution, so the task and its dependencies run again;
+// within one execution, a task shared by several dependents runs only once.
+// When called by a Runner or Workflow, the task joins that run's execution.
+// A new execution first checks the dependencies for cycles, which would never
+// complete, and returns a *CycleError without running anything if it finds one.
 func (t *Task[In, Out]) Run(ctx context.Context, input any) (any, error) {
-	t.once.Do(func() {
-		var currInput any = input
+	x := executionFrom(ctx)
+	if x == nil {
+		if _, err := NewGraph(t); err != nil {
+			var cycleErr *CycleError
+			if errors.As(err, &cycleErr) {
+				return nil, cycleErr
+			}
+		}
+		x = newExecution()
+		ctx = withExecution(ctx, x)
+	}
+	return x.run(ctx, t, input)
+}
+
+// execute runs the task's dependencies and then its function, recording the
+// outcome in Result and Err. It also returns the number of calls to Fn.
+func (t *Task[In, Out]) execute(ctx context.Context, input any) (any, int, error) {
+	result, attempts, err := t.call(ctx, input)
+
+	t.mu.Lock()
+	t.Result, t.Err = result, err
+	t.mu.Unlock()
+
+	return result, attempts, err
+}
+
+// call resolves the task's input from its dependencies and calls Fn, applying
+// the task's timeout and retry policy.
+func (t *Task[In, Out]) call(ctx context.Context, input any) (Out, int, error) {
+	var zeroOut Out
+
+	currInput, err := t.runDependencies(ctx, input)
+	if err != nil {
+		t.log(ctx, LevelError, "task dependency failed", 0, slog.Any("error", err))
+		return zeroOut, 0, err
+	}
+
+	// Don't start the task once the context is done, e.g. because
+	// another task failed and the runner cancelled the run.
+	if err := ctx.Err(); err != nil {
+		t.log(ctx, LevelInfo, "task skipped", 0, slog.Any("error", err))
+		return zeroOut, 0, err
+	}
+
+	var in In
+	if currInput != nil {
+		typedInput, ok := currInput.(In)
+		if !ok {
+			err := fmt.Errorf("task: input type mismatch: expected %T, got %T", in, currInput)
+			t.log(ctx, LevelError, "task input type mismatch", 0, slog.Any("error", err))
+			return zeroOut, 0, err
+		}
+		in = typedInput
+	}
+
+	x := executionFrom(ctx)
+	ctx = x.startTask(ctx, t, Event{Input: in})
+
+	attempts := 0
+	attempt := func(ctx context.Context) (Out, error) {
+		attempts++
+		t.log(ctx, LevelDebug, "task attempt started", attempts)
+		start := time.Now()
+		ctx = x.start(ctx, t, Event{Type: EventAttemptStarted, Time: start, Attempt: attempts})
+		if t.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, t.Timeout)
+			defer cancel()
+		}
+
+		result, err := safeCall(ctx, t.Name, t.Repanic, t.Fn, in)
+		var panicErr *PanicError
+		if errors.As(err, &panicErr) {
+			t.log(ctx, LevelError, "task panicked", attempts,
+				slog.Any("panic", panicErr.Value), slog.String("stack", string(panicErr.Stack)))
+		}
+		end := time.Now()
+		x.emit(ctx, t, Event{
+			Type:     EventAttemptFinished,
+			Time:     end,
+			Start:    start,
+			Duration: end.Sub(start),
+			Attempt:  attempts,
+			Err:      err,
+		})
+		return result, err
+	}
 
+	if t.RetryPolicy == nil {
+		result, err := attempt(ctx)
+		return result, attempts, err
+	}
+
+	policy := *t.RetryPolicy
+	onRetry := policy.OnRetry
+	policy.OnRetry = func(next int, delay time.Duration, err error) {
+		t.log(ctx, LevelWarn, "task attempt failed, retrying", next-1,
+			slog.Duration("delay", delay), slog.Any("error", err))
+		x.emit(ctx, t, Event{Type: EventTaskRetrying, Attempt: next - 1, Delay: delay, Err: err})
+		if onRetry != nil {
+			onRetry(next, delay, err)
+		}
+	}
+
+	var result Out
+	err = policy.Do(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = attempt(ctx)
+		return err
+	})
+	if err != nil && attempts > 1 {
+		t.log(ctx, LevelError, "task failed after retries", attempts, slog.Any("error", err))
+	}
+
+	return result, attempts, err
+}
+
+// log sends a message to the task's logger with the task name, the run ID and,
+// if attempt is positive, the attempt number.
+func (t *Task[In, Out]) log(ctx context.Context, level Level, msg string, attempt int, fields ...Field) {
+	scoped := make([]Field, 0, len(fields)+3)
+	scoped = append(scoped, slog.String(FieldTask, t.Name))
+	if x := executionFrom(ctx); x != nil {
+		scoped = append(scoped, slog.String(FieldRunID, x.id))
+	}
+	if attempt > 0 {
+		scoped = append(scoped, slog.Int(FieldAttempt, attempt))
+	}
+	logMessage(ctx, t.Logger, level, msg, append(scoped, fields...)...)
+}
+
+// runDependencies executes the task's dependencies according to its DependencyMode
+// and returns the value to be used as the task's input.
+func (t *Task[In, Out]) runDependencies(ctx context.Context, input any) (any, error) {
+	if len(t.Depends) == 0 {
+		return input, nil
+	}
+
+	if t.DependencyMode == SequentialDependencies {
+		currInput := input
 		for _, dep := range t.Depends {
-			output, err := dep.Run(ctx, currInput)
+			output, err := runExecutable(ctx, dep, currInput)
 			if err != nil {
-				t.Logger.Log(fmt.Sprintf("task %s dependency failed: %v", t.Name, err))
-				t.Err = err
-				return
+				return nil, err
 			}
 			currInput = output
 		}
+		return currInput, nil
+	}
 
-		var in In
-		if currInput != nil {
-			typedInput, ok := currInput.(In)
-			if !ok {
-				err := fmt.Errorf("task: input type mismatch: expected %T, got %T", in, currInput)
-				t.Logger.Log(err.Error())
-				t.Err = err
-				return
-			}
-			in = typedInput
-		} else {
-			var zeroIn In
-			in = zeroIn
-		}
+	if len(t.Depends) == 1 {
+		return runExecutable(ctx, t.Depends[0], input)
+	}
 
-		t.Result, t.Err = t.Fn(ctx, in)
-	})
+	outputs := make([]any, len(t.Depends))
+	errs := make([]error, len(t.Depends))
+	var wg sync.WaitGroup
+
+	for i, dep := range t.Depends {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			outputs[i], errs[i] = runExecutable(ctx, dep, input)
+		}()
+	}
+
+	wg.Wait()
+
+	// Report the first failing dependency in declaration order, so the error
+	// does not depend on which goroutine happened to finish first.
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	return t.Result, t.Err
+	return outputs, nil
 }
 
-// GetResult returns the result of the task execution.
+// GetResult returns the result of the most recent task execution.
 func (t *Task[In, Out]) GetResult() any {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	return t.Result
 }
+
+// restoreResult decodes a result persisted by a StateStore.
+func (t *Task[In, Out]) restoreResult(data json.RawMessage) (any, error) {
+	var out Out
+	if len(data) == 0 {
+		return out, nil
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// observers returns the task's own observers.
+func (t *Task[In, Out]) observers() []Observer {
+	return t.Observers
+}
+
+// GetName returns the name of the task.
+func (t *Task[In, Out]) GetName() string {
+	return t.Name
+}
+
+// GetDependencies returns the dependencies of the task.
+func (t *Task[In, Out]) GetDependencies() []Executable {
+	return t.Depends
+}
+
+// GetTags returns the groups the task belongs to.
+func (t *Task[In, Out]) GetTags() []string {
+	return t.Tags
+}
+
+// runsDependenciesInline reports whether the task runs its dependencies itself
+// instead of leaving them to a scheduler. Sequential chains must, since each
+// dependency's input is the output of the previous one.
+func (t *Task[In, Out]) runsDependenciesInline() bool {
+	return t.DependencyMode == SequentialDependencies
+}
+
+// InputType returns the type of the task's input.
+func (t *Task[In, Out]) InputType() reflect.Type {
+	return reflect.TypeFor[In]()
+}
+
+// OutputType returns the type of the task's output.
+func (t *Task[In, Out]) OutputType() reflect.Type {
+	return reflect.TypeFor[Out]()
+}
+
+// checkInputTypes reports every dependency whose output cannot be delivered
+// to the task, or to the next dependency in a sequential chain.
+func (t *Task[In, Out]) checkInputTypes() []error {
+	var errs []error
+	check := func(producer Executable, consumer Executable, input reflect.Type) {
+		typed, ok := producer.(Typed)
+		if !ok {
+			return
+		}
+		if output := typed.OutputType(); !assignable(output, input) {
+			errs = append(errs, &TypeMismatchError{
+				Producer: nodeName(producer),
+				Consumer: nodeName(consumer),
+				Output:   output,
+				Input:    input,
+			})
+		}
+	}
+
+	switch {
+	case len(t.Depends) == 0:
+	case t.inputTypes != nil:
+		for i, dep := range t.Depends {
+			if i < len(t.inputTypes) {
+				check(dep, t, t.inputTypes[i])
+			}
+		}
+	case t.DependencyMode == SequentialDependencies:
+		for i := 1; i < len(t.Depends); i++ {
+			next, ok := t.Depends[i].(Typed)
+			if !ok || len(dependenciesOf(t.Depends[i])) > 0 {
+				continue // its input comes from its own dependencies
+			}
+			check(t.Depends[i-1], t.Depends[i], next.InputType())
+		}
+		check(t.Depends[len(t.Depends)-1], t, t.InputType())
+	case len(t.Depends) == 1:
+		check(t.Depends[0], t, t.InputType())
+	default:
+		if input := t.InputType(); !assignable(reflect.TypeFor[[]any](), input) {
+			errs = append(errs, &TypeMismatchError{
+				Producer: fmt.Sprintf("%d dependencies", len(t.Depends)),
+				Consumer: t.Name,
+				Output:   reflect.TypeFor[[]any](),
+				Input:    input,
+			})
+		}
+	}
+
+	return errs
+}
@@ -5,19 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/josuedeavila/taskflow"
 )
 
-type slogLogger struct {
-	*slog.Logger
-}
-
-func (l *slogLogger) Log(v ...any) {
-	slog.Info("TaskFlow Log", "message", fmt.Sprint(v...))
-}
 func TestNewTask(t *testing.T) {
 	t.Run("create task with name and function", func(t *testing.T) {
 		fn := func(ctx context.Context, input string) (int, error) {
@@ -44,8 +39,7 @@ func TestNewTask(t *testing.T) {
 			return len(input), nil
 		}
 
-		l := slog.Default()
-		logger := &slogLogger{Logger: l}
+		logger := taskflow.NewSlogLogger(slog.Default())
 		task := taskflow.NewTask("test_with_logger", fn).WithLogger(logger)
 
 		if task.Logger == nil {
@@ -169,7 +163,7 @@ func TestTaskRunWithDependencies(t *testing.T) {
 
 	mainTask := taskflow.NewTask("main", func(ctx context.Context, input string) (int, error) {
 		return len(input), nil
-	}).After(task1, task2)
+	}).WithDependencyMode(taskflow.SequentialDependencies).After(task1, task2)
 
 	result, err := mainTask.Run(context.Background(), nil)
 
@@ -181,6 +175,63 @@ func TestTaskRunWithDependencies(t *testing.T) {
 	}
 }
 
+func TestTaskRunWithParallelDependencies(t *testing.T) {
+	newDep := func(name string) *taskflow.Task[any, string] {
+		return taskflow.NewTask(name, func(ctx context.Context, input any) (string, error) {
+			time.Sleep(50 * time.Millisecond)
+			return name, nil
+		})
+	}
+
+	var received []any
+	mainTask := taskflow.NewTask("main", func(ctx context.Context, input []any) (int, error) {
+		received = input
+		return len(input), nil
+	}).After(newDep("users"), newDep("products"), newDep("orders"))
+
+	start := time.Now()
+	result, err := mainTask.Run(context.Background(), nil)
+	duration := time.Since(start)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != 3 {
+		t.Errorf("Expected result 3, got %v", result)
+	}
+
+	expected := []any{"users", "products", "orders"}
+	for i, want := range expected {
+		if received[i] != want {
+			t.Errorf("Expected input[%d] %v, got %v", i, want, received[i])
+		}
+	}
+
+	// Dependencies run concurrently, so the total is close to a single dependency's latency
+	if duration > 120*time.Millisecond {
+		t.Errorf("Dependencies appear to run sequentially. Total time: %v", duration)
+	}
+}
+
+func TestTaskRunWithSingleParallelDependency(t *testing.T) {
+	dep := taskflow.NewTask("dep", func(ctx context.Context, input any) (string, error) {
+		return "hello", nil
+	})
+
+	mainTask := taskflow.NewTask("main", func(ctx context.Context, input string) (int, error) {
+		return len(input), nil
+	}).After(dep)
+
+	result, err := mainTask.Run(context.Background(), nil)
+
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if result != 5 {
+		t.Errorf("Expected result 5, got %v", result)
+	}
+}
+
 func TestTaskRunWithDependencyError(t *testing.T) {
 	expectedErr := errors.New("dependency error")
 
@@ -202,9 +253,32 @@ func TestTaskRunWithDependencyError(t *testing.T) {
 	}
 }
 
-func TestTaskRunOnce(t *testing.T) {
+func TestTaskRunWithCycle(t *testing.T) {
+	a := newNamedTask("a")
+	b := newNamedTask("b")
+	a.After(b)
+	b.After(a)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := a.Run(context.Background(), nil)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		var cycleErr *taskflow.CycleError
+		if !errors.As(err, &cycleErr) {
+			t.Errorf("Expected CycleError, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Run to return, it blocked")
+	}
+}
+
+func TestTaskRunRerun(t *testing.T) {
 	callCount := 0
-	task := taskflow.NewTask("once_task", func(ctx context.Context, input any) (int, error) {
+	task := taskflow.NewTask("rerun_task", func(ctx context.Context, input any) (int, error) {
 		callCount++
 		return callCount, nil
 	})
@@ -219,11 +293,44 @@ func TestTaskRunOnce(t *testing.T) {
 	if result1 != 1 {
 		t.Errorf("Expected first result 1, got %v", result1)
 	}
-	if result2 != 1 {
-		t.Errorf("Expected second result 1 (cached), got %v", result2)
+	if result2 != 2 {
+		t.Errorf("Expected second result 2, got %v", result2)
+	}
+	if callCount != 2 {
+		t.Errorf("Expected function to be called twice, was called %d times", callCount)
 	}
-	if callCount != 1 {
-		t.Errorf("Expected function to be called once, was called %d times", callCount)
+	if task.GetResult() != 2 {
+		t.Errorf("Expected GetResult to return the latest result 2, got %v", task.GetResult())
+	}
+}
+
+func TestTaskRunSharedDependencyOncePerExecution(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	shared := taskflow.NewTask("shared", func(ctx context.Context, input any) (string, error) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		return "shared", nil
+	})
+
+	left := taskflow.NewTask("left", func(ctx context.Context, input string) (string, error) {
+		return input + "_left", nil
+	}).After(shared)
+	right := taskflow.NewTask("right", func(ctx context.Context, input string) (string, error) {
+		return input + "_right", nil
+	}).After(shared)
+	join := taskflow.NewTask("join", func(ctx context.Context, input []any) (int, error) {
+		return len(input), nil
+	}).After(left, right)
+
+	for i := 1; i <= 2; i++ {
+		if _, err := join.Run(context.Background(), nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if calls != i {
+			t.Errorf("Expected shared dependency to run once per execution (%d), ran %d times", i, calls)
+		}
 	}
 }
 
@@ -257,22 +364,27 @@ func TestTaskRunConcurrent(t *testing.T) {
 	})
 
 	const numGoroutines = 10
-	results := make(chan int, numGoroutines)
+	type outcome struct {
+		input  int
+		result int
+	}
+	results := make(chan outcome, numGoroutines)
 	errors := make(chan error, numGoroutines)
 
+	start := time.Now()
 	for i := 0; i < numGoroutines; i++ {
 		go func(input int) {
 			result, err := task.Run(context.Background(), input)
 			if err != nil {
 				errors <- err
 			} else {
-				results <- result.(int)
+				results <- outcome{input: input, result: result.(int)}
 			}
 		}(i)
 	}
 
 	// Collect results
-	var resultSlice []int
+	var resultSlice []outcome
 	var errorSlice []error
 
 	for i := 0; i < numGoroutines; i++ {
@@ -290,13 +402,97 @@ func TestTaskRunConcurrent(t *testing.T) {
 		t.Errorf("Unexpected errors: %v", errorSlice)
 	}
 
-	// All goroutines should get the same result (first one to execute)
-	if len(resultSlice) > 0 {
-		expectedResult := resultSlice[0]
-		for _, result := range resultSlice {
-			if result != expectedResult {
-				t.Errorf("Expected all results to be %d, got %d", expectedResult, result)
-			}
+	// Every execution is isolated and gets the result for its own input
+	for _, o := range resultSlice {
+		if o.result != o.input*2 {
+			t.Errorf("Expected result %d for input %d, got %d", o.input*2, o.input, o.result)
 		}
 	}
+
+	if duration := time.Since(start); duration > 200*time.Millisecond {
+		t.Errorf("Expected executions to run concurrently, took %v", duration)
+	}
+}
+
+func TestTaskWithRetry(t *testing.T) {
+	callCount := 0
+	var logs []string
+
+	task := taskflow.NewTask("flaky", func(ctx context.Context, input any) (string, error) {
+		callCount++
+		if callCount < 3 {
+			return "", errors.New("temporary failure")
+		}
+		return "ok", nil
+	}).WithRetry(taskflow.RetryPolicy{
+		MaxRetries: 3,
+		Strategy:   taskflow.ConstantBackoff,
+		BaseDelay:  time.Millisecond,
+	}).WithLogger(taskflow.LoggerFunc(func(args ...any) {
+		logs = append(logs, fmt.Sprint(args...))
+	}))
+
+	runner := taskflow.NewRunner()
+	runner.Add(task)
+
+	report, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	rec, _ := report.Task("flaky")
+	if rec.Attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", rec.Attempts)
+	}
+	if rec.Result != "ok" {
+		t.Errorf("Expected result 'ok', got %v", rec.Result)
+	}
+	if len(logs) != 2 || !strings.Contains(logs[0], "attempt=1") || !strings.Contains(logs[1], "attempt=2") {
+		t.Errorf("Expected retries to be logged with their attempt, got %v", logs)
+	}
+}
+
+func TestTaskWithTimeout(t *testing.T) {
+	task := taskflow.NewTask("slow", func(ctx context.Context, input any) (string, error) {
+		select {
+		case <-time.After(200 * time.Millisecond):
+			return "completed", nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}).WithTimeout(10 * time.Millisecond)
+
+	start := time.Now()
+	_, err := task.Run(context.Background(), nil)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if duration := time.Since(start); duration > 100*time.Millisecond {
+		t.Errorf("Expected the timeout to stop the task, took %v", duration)
+	}
+}
+
+func TestTaskWithTimeoutPerAttempt(t *testing.T) {
+	callCount := 0
+	task := taskflow.NewTask("hanging_once", func(ctx context.Context, input any) (string, error) {
+		callCount++
+		if callCount == 1 {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return "recovered", nil
+	}).WithTimeout(10 * time.Millisecond).WithRetry(taskflow.RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond})
+
+	result, err := task.Run(context.Background(), nil)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != "recovered" {
+		t.Errorf("Expected result 'recovered', got %v", result)
+	}
+	if callCount != 2 {
+		t.Errorf("Expected 2 calls, got %d", callCount)
+	}
 }
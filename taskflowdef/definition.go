@@ -0,0 +1,441 @@
+// Package taskflowdef loads taskflow workflows from YAML or JSON definitions.
+// A definition lists tasks with their dependencies, retry, timeout and fan-out
+// settings, and names the Go function of each task in a Registry, so that
+// pipelines can be rewired without recompiling.
+package taskflowdef
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"maps"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/josuedeavila/taskflow"
+	"gopkg.in/yaml.v3"
+)
+
+// Definition describes a workflow, see Parse.
+type Definition struct {
+	Name           string            `yaml:"name"`
+	ErrorMode      string            `yaml:"error_mode"`      // fail_fast (default) or continue_on_error
+	MaxConcurrency int               `yaml:"max_concurrency"` // Maximum number of tasks running at once, unlimited if zero
+	GroupLimits    map[string]int    `yaml:"group_limits"`    // Maximum number of tasks running at once per tag
+	Tasks          []*TaskDefinition `yaml:"tasks"`
+
+	file string
+	pos  positions
+}
+
+// TaskDefinition describes a task of a workflow. A task either calls the
+// function registered as Func, or fans out over its input, see FanOut.
+type TaskDefinition struct {
+	Name           string            `yaml:"name"`
+	Func           string            `yaml:"func"`            // Name of the task function or kind in the registry
+	Params         map[string]any    `yaml:"params"`          // Settings of the task kind, see RegisterKind
+	Depends        []string          `yaml:"depends"`         // Names of the tasks to run first
+	DependencyMode string            `yaml:"dependency_mode"` // parallel (default) or sequential
+	Tags           []string          `yaml:"tags"`
+	Timeout        time.Duration     `yaml:"timeout"` // Time limit for each attempt, like 30s
+	Retry          *RetryDefinition  `yaml:"retry"`
+	FanOut         *FanOutDefinition `yaml:"fan_out"`
+
+	pos    positions
+	params *yaml.Node
+}
+
+// RetryDefinition describes the retry policy of a task, see taskflow.RetryPolicy.
+type RetryDefinition struct {
+	MaxRetries int           `yaml:"max_retries"`
+	Strategy   string        `yaml:"strategy"` // exponential (default), constant, linear or decorrelated_jitter
+	BaseDelay  time.Duration `yaml:"base_delay"`
+	MaxDelay   time.Duration `yaml:"max_delay"`
+	MaxElapsed time.Duration `yaml:"max_elapsed"`
+
+	pos positions
+}
+
+// FanOutDefinition describes a task calling a function once per element of its
+// input concurrently, then combining the results, see taskflow.FanOutTask.
+type FanOutDefinition struct {
+	Map            string `yaml:"map"`    // Name of the function called per element, taking In and returning Out
+	FanIn          string `yaml:"fan_in"` // Name of the function combining the results, taking []Out and returning Out
+	MaxConcurrency int    `yaml:"max_concurrency"`
+	CancelOnError  bool   `yaml:"cancel_on_error"`
+
+	pos positions
+}
+
+var (
+	errorModes = map[string]taskflow.ErrorMode{
+		"fail_fast":         taskflow.FailFast,
+		"continue_on_error": taskflow.ContinueOnError,
+	}
+	dependencyModes = map[string]taskflow.DependencyMode{
+		"parallel":   taskflow.ParallelDependencies,
+		"sequential": taskflow.SequentialDependencies,
+	}
+	strategies = map[string]taskflow.BackoffStrategy{
+		"exponential":         taskflow.ExponentialBackoff,
+		"constant":            taskflow.ConstantBackoff,
+		"linear":              taskflow.LinearBackoff,
+		"decorrelated_jitter": taskflow.DecorrelatedJitterBackoff,
+	}
+)
+
+// Error is a problem in a definition, located at a line of its file.
+type Error struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Line == 0 && e.File == "":
+		// Definitions built by hand have no positions.
+		return e.Msg
+	case e.Line == 0:
+		return fmt.Sprintf("%s: %s", e.File, e.Msg)
+	case e.File == "":
+		return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+	}
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+}
+
+// errorList collects the errors of a definition.
+type errorList struct {
+	file string
+	errs []error
+}
+
+func (l *errorList) add(line int, format string, args ...any) {
+	l.errs = append(l.errs, &Error{File: l.file, Line: line, Msg: fmt.Sprintf(format, args...)})
+}
+
+func (l *errorList) err() error {
+	return errors.Join(l.errs...)
+}
+
+// ParseFile reads and parses the definition in the file at path.
+func ParseFile(path string) (*Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(path, data)
+}
+
+// Parse parses a definition in YAML or JSON and validates its structure: unknown
+// or missing fields, invalid settings, duplicate task names, dependencies on
+// undefined tasks and dependency cycles. The file name only appears in errors,
+// which are *Error values joined together.
+func Parse(file string, data []byte) (*Definition, error) {
+	l := &errorList{file: file}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		l.addYAML(err)
+		return nil, l.err()
+	}
+	if len(root.Content) == 0 {
+		l.add(1, "empty definition")
+		return nil, l.err()
+	}
+
+	d := &Definition{file: file}
+	if err := root.Content[0].Decode(d); err != nil {
+		l.addYAML(err)
+		return nil, l.err()
+	}
+	d.validate(l)
+	if err := l.err(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+var (
+	// yamlLine matches the line yaml prefixes its error messages with.
+	yamlLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	// yamlParserError matches the syntax errors yaml reports with a line
+	// numbered from zero, unlike the others.
+	yamlParserError = regexp.MustCompile(`^did not find expected (<document start>|node content|key|'-' indicator|',' or '[\]}]')$`)
+)
+
+// addYAML adds the errors reported by the yaml package.
+func (l *errorList) addYAML(err error) {
+	var messages []string
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		messages = typeErr.Errors
+	} else {
+		messages = []string{err.Error()}
+	}
+
+	for _, msg := range messages {
+		line := 1
+		if m := yamlLine.FindStringSubmatch(msg); m != nil {
+			line, _ = strconv.Atoi(m[1])
+			msg = m[2]
+			if yamlParserError.MatchString(msg) {
+				line++
+			}
+		}
+		l.add(line, "%s", strings.TrimPrefix(msg, "yaml: "))
+	}
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (d *Definition) UnmarshalYAML(node *yaml.Node) error {
+	type plain Definition
+	return decode(node, (*plain)(d), &d.pos)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (t *TaskDefinition) UnmarshalYAML(node *yaml.Node) error {
+	type plain TaskDefinition
+	if err := decode(node, (*plain)(t), &t.pos); err != nil {
+		return err
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "params" {
+			t.params = node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (r *RetryDefinition) UnmarshalYAML(node *yaml.Node) error {
+	type plain RetryDefinition
+	return decode(node, (*plain)(r), &r.pos)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (f *FanOutDefinition) UnmarshalYAML(node *yaml.Node) error {
+	type plain FanOutDefinition
+	return decode(node, (*plain)(f), &f.pos)
+}
+
+// positions holds the lines of a mapping and of its fields.
+type positions struct {
+	line    int
+	fields  map[string]int   // Line of each field
+	items   map[string][]int // Lines of the items of sequence fields
+	unknown []field          // Fields without a matching struct field
+}
+
+// field is a key of a mapping and its line.
+type field struct {
+	name string
+	line int
+}
+
+// of returns the line of a field, or of the mapping if it is absent.
+func (p positions) of(name string) int {
+	if line, ok := p.fields[name]; ok {
+		return line
+	}
+	return p.line
+}
+
+// item returns the line of an item of a sequence field.
+func (p positions) item(name string, i int) int {
+	if lines := p.items[name]; i < len(lines) {
+		return lines[i]
+	}
+	return p.of(name)
+}
+
+// decode decodes a mapping node into v, a pointer to a struct with yaml tags,
+// recording the positions of its fields.
+func decode(node *yaml.Node, v any, pos *positions) error {
+	if node.Kind != yaml.MappingNode {
+		return &yaml.TypeError{Errors: []string{fmt.Sprintf("line %d: expected a mapping", node.Line)}}
+	}
+
+	known := make(map[string]bool)
+	for tag := range yamlTags(v) {
+		known[tag] = true
+	}
+
+	*pos = positions{line: node.Line, fields: make(map[string]int), items: make(map[string][]int)}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if !known[key.Value] {
+			pos.unknown = append(pos.unknown, field{name: key.Value, line: key.Line})
+			continue
+		}
+		pos.fields[key.Value] = key.Line
+		if value.Kind == yaml.SequenceNode {
+			for _, item := range value.Content {
+				pos.items[key.Value] = append(pos.items[key.Value], item.Line)
+			}
+		}
+	}
+	return node.Decode(v)
+}
+
+// yamlTags returns the field names of the struct v points to.
+func yamlTags(v any) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		typ := reflect.TypeOf(v).Elem()
+		for i := range typ.NumField() {
+			name, _, _ := strings.Cut(typ.Field(i).Tag.Get("yaml"), ",")
+			if name != "" && name != "-" && !yield(name) {
+				return
+			}
+		}
+	}
+}
+
+// validate checks the structure of the definition.
+func (d *Definition) validate(l *errorList) {
+	d.pos.checkUnknown(l)
+	if _, ok := errorModes[d.ErrorMode]; d.ErrorMode != "" && !ok {
+		l.add(d.pos.of("error_mode"), "unknown error mode %q, expected fail_fast or continue_on_error", d.ErrorMode)
+	}
+	if d.MaxConcurrency < 0 {
+		l.add(d.pos.of("max_concurrency"), "max_concurrency must not be negative")
+	}
+	for _, group := range slices.Sorted(maps.Keys(d.GroupLimits)) {
+		if d.GroupLimits[group] < 1 {
+			l.add(d.pos.of("group_limits"), "group_limits: limit of %q must be at least 1", group)
+		}
+	}
+	if len(d.Tasks) == 0 {
+		l.add(d.pos.of("tasks"), "no tasks defined")
+	}
+
+	defined := make(map[string]*TaskDefinition)
+	for i, t := range d.Tasks {
+		if t == nil {
+			l.add(d.pos.item("tasks", i), "empty task")
+			continue
+		}
+		t.validate(l)
+		if t.Name == "" {
+			continue
+		}
+		if prev, ok := defined[t.Name]; ok {
+			l.add(t.pos.of("name"), "task %q is already defined at line %d", t.Name, prev.pos.of("name"))
+			continue
+		}
+		defined[t.Name] = t
+	}
+
+	for _, t := range d.Tasks {
+		if t == nil {
+			continue
+		}
+		for i, dep := range t.Depends {
+			if _, ok := defined[dep]; !ok {
+				l.add(t.pos.item("depends", i), "task %q depends on undefined task %q", t.Name, dep)
+			}
+		}
+	}
+
+	d.checkCycles(l, defined)
+}
+
+// validate checks the settings of the task.
+func (t *TaskDefinition) validate(l *errorList) {
+	t.pos.checkUnknown(l)
+	if t.Name == "" {
+		l.add(t.pos.line, "task without a name")
+	}
+	switch {
+	case t.Func == "" && t.FanOut == nil:
+		l.add(t.pos.line, "task %q needs either func or fan_out", t.Name)
+	case t.Func != "" && t.FanOut != nil:
+		l.add(t.pos.of("fan_out"), "task %q has both func and fan_out", t.Name)
+	}
+	if t.FanOut != nil && t.Params != nil {
+		l.add(t.pos.of("params"), "task %q has params, which only apply to func", t.Name)
+	}
+	if _, ok := dependencyModes[t.DependencyMode]; t.DependencyMode != "" && !ok {
+		l.add(t.pos.of("dependency_mode"), "unknown dependency mode %q, expected parallel or sequential", t.DependencyMode)
+	}
+	if t.Timeout < 0 {
+		l.add(t.pos.of("timeout"), "timeout must not be negative")
+	}
+
+	if r := t.Retry; r != nil {
+		r.pos.checkUnknown(l)
+		if _, ok := strategies[r.Strategy]; r.Strategy != "" && !ok {
+			l.add(r.pos.of("strategy"), "unknown retry strategy %q, expected exponential, constant, linear or decorrelated_jitter", r.Strategy)
+		}
+		if r.MaxRetries < 0 {
+			l.add(r.pos.of("max_retries"), "max_retries must not be negative")
+		}
+	}
+
+	if f := t.FanOut; f != nil {
+		f.pos.checkUnknown(l)
+		if f.Map == "" {
+			l.add(f.pos.line, "fan_out of task %q needs a map function", t.Name)
+		}
+		if f.FanIn == "" {
+			l.add(f.pos.line, "fan_out of task %q needs a fan_in function", t.Name)
+		}
+		if f.MaxConcurrency < 0 {
+			l.add(f.pos.of("max_concurrency"), "max_concurrency must not be negative")
+		}
+	}
+}
+
+// checkUnknown reports the unknown fields.
+func (p positions) checkUnknown(l *errorList) {
+	for _, f := range p.unknown {
+		l.add(f.line, "unknown field %q", f.name)
+	}
+}
+
+// checkCycles reports each dependency cycle at the dependency closing it.
+func (d *Definition) checkCycles(l *errorList, defined map[string]*TaskDefinition) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	var stack []string
+
+	var visit func(t *TaskDefinition)
+	visit = func(t *TaskDefinition) {
+		state[t.Name] = visiting
+		stack = append(stack, t.Name)
+		for i, name := range t.Depends {
+			dep, ok := defined[name]
+			if !ok {
+				continue
+			}
+			switch state[name] {
+			case unvisited:
+				visit(dep)
+			case visiting:
+				start := len(stack) - 1
+				for stack[start] != name {
+					start--
+				}
+				path := append(append([]string(nil), stack[start:]...), name)
+				l.add(t.pos.item("depends", i), "dependency cycle: %s", strings.Join(path, " -> "))
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[t.Name] = visited
+	}
+
+	for _, t := range d.Tasks {
+		if t != nil && t.Name != "" && defined[t.Name] == t && state[t.Name] == unvisited {
+			visit(t)
+		}
+	}
+}
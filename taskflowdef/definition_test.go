@@ -0,0 +1,177 @@
+package taskflowdef_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josuedeavila/taskflow/taskflowdef"
+)
+
+const pipeline = `name: orders
+error_mode: continue_on_error
+max_concurrency: 4
+group_limits:
+  db: 1
+tasks:
+  - name: fetch
+    func: fetch
+    tags: [db]
+    timeout: 5s
+    retry:
+      max_retries: 3
+      strategy: constant
+      base_delay: 100ms
+  - name: total
+    func: sum
+    depends: [fetch]
+`
+
+func TestParse(t *testing.T) {
+	d, err := taskflowdef.Parse("orders.yaml", []byte(pipeline))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if d.Name != "orders" || d.ErrorMode != "continue_on_error" || d.MaxConcurrency != 4 || d.GroupLimits["db"] != 1 {
+		t.Errorf("Expected workflow settings, got %+v", d)
+	}
+	if len(d.Tasks) != 2 {
+		t.Fatalf("Expected 2 tasks, got %d", len(d.Tasks))
+	}
+	fetch := d.Tasks[0]
+	if fetch.Timeout != 5*time.Second || fetch.Retry == nil || fetch.Retry.BaseDelay != 100*time.Millisecond {
+		t.Errorf("Expected timeout and retry settings, got %+v", fetch)
+	}
+	if deps := d.Tasks[1].Depends; len(deps) != 1 || deps[0] != "fetch" {
+		t.Errorf("Expected total to depend on fetch, got %v", deps)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	data := `{
+	"name": "orders",
+	"tasks": [
+		{"name": "fetch", "func": "fetch", "timeout": "5s"},
+		{"name": "total", "func": "sum", "depends": ["fetch"]}
+	]
+}`
+	d, err := taskflowdef.Parse("orders.json", []byte(data))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(d.Tasks) != 2 || d.Tasks[0].Timeout != 5*time.Second {
+		t.Errorf("Expected 2 tasks, got %+v", d.Tasks)
+	}
+
+	_, err = taskflowdef.Parse("orders.json", []byte(strings.Replace(data, `"func": "sum"`, `"fn": "sum"`, 1)))
+	assertErrors(t, err,
+		`orders.json:5: unknown field "fn"`,
+		`orders.json:5: task "total" needs either func or fan_out`)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected []string
+	}{
+		{
+			name: "syntax",
+			data: "name: broken\ntasks: [a, b\n",
+			expected: []string{
+				"wf.yaml:2: did not find expected ',' or ']'",
+			},
+		},
+		{
+			name: "types",
+			data: "max_concurrency: many\ntasks:\n  - name: a\n    func: a\n    timeout: soon\n",
+			expected: []string{
+				"wf.yaml:1: cannot unmarshal !!str `many` into int",
+				"wf.yaml:5: cannot unmarshal !!str `soon` into time.Duration",
+			},
+		},
+		{
+			name:     "no tasks",
+			data:     "name: empty\n",
+			expected: []string{"wf.yaml:1: no tasks defined"},
+		},
+		{
+			name: "settings",
+			data: `error_mode: retry
+group_limits: {db: 0}
+tasks:
+  - name: a
+    func: a
+    dependency_mode: random
+    retries: 3
+    retry:
+      strategy: fibonacci
+  - func: b
+  - name: c
+  - name: d
+    func: d
+    fan_out:
+      map: m
+`,
+			expected: []string{
+				`wf.yaml:1: unknown error mode "retry", expected fail_fast or continue_on_error`,
+				`wf.yaml:2: group_limits: limit of "db" must be at least 1`,
+				`wf.yaml:7: unknown field "retries"`,
+				`wf.yaml:6: unknown dependency mode "random", expected parallel or sequential`,
+				`wf.yaml:9: unknown retry strategy "fibonacci", expected exponential, constant, linear or decorrelated_jitter`,
+				`wf.yaml:10: task without a name`,
+				`wf.yaml:11: task "c" needs either func or fan_out`,
+				`wf.yaml:14: task "d" has both func and fan_out`,
+				`wf.yaml:15: fan_out of task "d" needs a fan_in function`,
+			},
+		},
+		{
+			name: "dependencies",
+			data: `tasks:
+  - name: a
+    func: a
+    depends: [c]
+  - name: b
+    func: b
+    depends:
+      - a
+      - missing
+  - name: c
+    func: c
+    depends: [b]
+  - name: a
+    func: a
+`,
+			expected: []string{
+				`wf.yaml:13: task "a" is already defined at line 2`,
+				`wf.yaml:9: task "b" depends on undefined task "missing"`,
+				`wf.yaml:8: dependency cycle: a -> c -> b -> a`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := taskflowdef.Parse("wf.yaml", []byte(tt.data))
+			assertErrors(t, err, tt.expected...)
+		})
+	}
+}
+
+// assertErrors checks that err joins errors with the expected messages, in order.
+func assertErrors(t *testing.T, err error, expected ...string) {
+	t.Helper()
+
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	var defErr *taskflowdef.Error
+	if !errors.As(err, &defErr) {
+		t.Errorf("Expected *taskflowdef.Error, got %T", err)
+	}
+	if got := strings.Split(err.Error(), "\n"); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected errors:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
@@ -0,0 +1,9 @@
+module github.com/josuedeavila/taskflow/taskflowdef
+
+go 1.24.0
+
+require github.com/josuedeavila/taskflow v0.1.0
+
+require gopkg.in/yaml.v3 v3.0.1
+
+replace github.com/josuedeavila/taskflow => ../
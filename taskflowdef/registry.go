@@ -0,0 +1,321 @@
+package taskflowdef
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/josuedeavila/taskflow"
+	"gopkg.in/yaml.v3"
+)
+
+// Registry holds the task functions definitions refer to by name.
+type Registry struct {
+	funcs map[string]entry
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{funcs: make(map[string]entry)}
+}
+
+// Register adds fn to the registry under name, replacing any function
+// previously registered with that name.
+func Register[In any, Out any](r *Registry, name string, fn taskflow.TaskFunc[In, Out]) {
+	r.funcs[name] = registered[In, Out]{fn: fn}
+}
+
+// RegisterKind adds a kind of task to the registry under name. The function of
+// each task of this kind is created by newFunc, with the params of the task
+// decoded into P, typically a struct with yaml tags. Params that don't match a
+// field of P are reported as errors.
+func RegisterKind[P any, In any, Out any](r *Registry, name string, newFunc func(params P) (taskflow.TaskFunc[In, Out], error)) {
+	r.funcs[name] = kind[P, In, Out]{newFunc: newFunc}
+}
+
+// Names returns the names of the registered functions and kinds, sorted.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.funcs))
+	for name := range r.funcs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// entry is a registered task function or kind.
+type entry interface {
+	// resolve returns the function of a task with the given params, if any.
+	resolve(params *yaml.Node) (function, error)
+}
+
+// function is a task function with known types.
+type function interface {
+	// build returns the task of a definition calling the function, or fanning
+	// it out when the definition has FanOut and fanIn is set.
+	build(def *TaskDefinition, deps []taskflow.Executable, fanIn function, opts *options) (taskflow.Executable, error)
+	value() any
+	signature() string
+}
+
+// registered is a function with its types.
+type registered[In any, Out any] struct {
+	fn taskflow.TaskFunc[In, Out]
+}
+
+func (r registered[In, Out]) build(def *TaskDefinition, deps []taskflow.Executable, fanIn function, opts *options) (taskflow.Executable, error) {
+	if def.FanOut == nil {
+		return configure(taskflow.NewTask(def.Name, r.fn), def, deps, opts), nil
+	}
+
+	combine, ok := fanIn.value().(taskflow.TaskFunc[[]Out, Out])
+	if !ok {
+		return nil, fmt.Errorf("fan_in function %q is %s, expected %s to combine the results of %q",
+			def.FanOut.FanIn, fanIn.signature(), signature(reflect.TypeFor[[]Out](), reflect.TypeFor[Out]()), def.FanOut.Map)
+	}
+	f := &taskflow.FanOutTask[In, Out]{
+		Name:           def.Name,
+		Map:            r.fn,
+		FanIn:          combine,
+		MaxConcurrency: def.FanOut.MaxConcurrency,
+		CancelOnError:  def.FanOut.CancelOnError,
+	}
+	return configure(f.ToTask(), def, deps, opts), nil
+}
+
+func (r registered[In, Out]) resolve(params *yaml.Node) (function, error) {
+	if params != nil {
+		return nil, errors.New("params only apply to task kinds")
+	}
+	return r, nil
+}
+
+func (r registered[In, Out]) value() any {
+	return r.fn
+}
+
+func (r registered[In, Out]) signature() string {
+	return signature(reflect.TypeFor[In](), reflect.TypeFor[Out]())
+}
+
+// signature describes a task function with the given types.
+func signature(in, out reflect.Type) string {
+	return fmt.Sprintf("func(context.Context, %s) (%s, error)", in, out)
+}
+
+// kind creates functions from the params of tasks.
+type kind[P any, In any, Out any] struct {
+	newFunc func(params P) (taskflow.TaskFunc[In, Out], error)
+}
+
+func (k kind[P, In, Out]) resolve(params *yaml.Node) (function, error) {
+	var p P
+	if params != nil {
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+	}
+	fn, err := k.newFunc(p)
+	if err != nil {
+		return nil, err
+	}
+	return registered[In, Out]{fn: fn}, nil
+}
+
+// decodeParams decodes params into p, reporting the params without a matching
+// field when p points to a struct.
+func decodeParams(params *yaml.Node, p any) error {
+	if params.Kind == yaml.MappingNode && reflect.TypeOf(p).Elem().Kind() == reflect.Struct {
+		known := make(map[string]bool)
+		for tag := range yamlTags(p) {
+			known[tag] = true
+		}
+		var unknown []string
+		for i := 0; i+1 < len(params.Content); i += 2 {
+			if key := params.Content[i]; !known[key.Value] {
+				unknown = append(unknown, fmt.Sprintf("line %d: unknown param %q", key.Line, key.Value))
+			}
+		}
+		if len(unknown) > 0 {
+			return &yaml.TypeError{Errors: unknown}
+		}
+	}
+	return params.Decode(p)
+}
+
+// configure applies the settings of a definition to its task.
+func configure[In any, Out any](t *taskflow.Task[In, Out], def *TaskDefinition, deps []taskflow.Executable, opts *options) *taskflow.Task[In, Out] {
+	t.After(deps...).WithTags(def.Tags...).WithDependencyMode(dependencyModes[def.DependencyMode])
+	if opts.logger != nil {
+		t.WithLogger(opts.logger)
+	}
+	if def.Timeout > 0 {
+		t.WithTimeout(def.Timeout)
+	}
+	if r := def.Retry; r != nil {
+		t.WithRetry(taskflow.RetryPolicy{
+			MaxRetries: r.MaxRetries,
+			Strategy:   strategies[r.Strategy],
+			BaseDelay:  r.BaseDelay,
+			MaxDelay:   r.MaxDelay,
+			MaxElapsed: r.MaxElapsed,
+		})
+	}
+	return t
+}
+
+// Option configures how definitions are built.
+type Option func(*options)
+
+type options struct {
+	logger taskflow.Logger
+}
+
+// WithLogger sets the logger of every task.
+func WithLogger(logger taskflow.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// Build resolves the functions of the tasks in registry and returns a runner
+// for the workflow. It validates the definition as Parse does, so that it can
+// be built by hand, and also reports functions missing from the registry and
+// outputs that can't be delivered to the input of the tasks depending on them.
+func (d *Definition) Build(registry *Registry, opts ...Option) (*taskflow.Runner, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	l := &errorList{file: d.file}
+	d.validate(l)
+	if err := l.err(); err != nil {
+		return nil, err
+	}
+
+	// lookup returns the function registered as name, reporting errors at line.
+	lookup := func(name string, params *yaml.Node, line int) function {
+		e, ok := registry.funcs[name]
+		if !ok {
+			l.add(line, "unknown function %q", name)
+			return nil
+		}
+		fn, err := e.resolve(params)
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			l.addYAML(err)
+		} else if err != nil {
+			l.add(line, "%s: %v", name, err)
+		}
+		return fn
+	}
+
+	tasks := make(map[string]taskflow.Executable)
+	lines := make(map[string]int)
+	dependents := make(map[string]bool)
+	var build func(def *TaskDefinition) taskflow.Executable
+	build = func(def *TaskDefinition) taskflow.Executable {
+		if t, ok := tasks[def.Name]; ok {
+			return t
+		}
+		var deps []taskflow.Executable
+		for _, name := range def.Depends {
+			dependents[name] = true
+			if dep := build(d.task(name)); dep != nil {
+				deps = append(deps, dep)
+			}
+		}
+
+		var fn, fanIn function
+		if f := def.FanOut; f != nil {
+			fn, fanIn = lookup(f.Map, nil, f.pos.of("map")), lookup(f.FanIn, nil, f.pos.of("fan_in"))
+		} else {
+			fn = lookup(def.Func, def.paramsNode(), def.pos.of("func"))
+		}
+		if fn == nil || (def.FanOut != nil && fanIn == nil) || len(deps) < len(def.Depends) {
+			tasks[def.Name] = nil
+			return nil
+		}
+
+		t, err := fn.build(def, deps, fanIn, o)
+		if err != nil {
+			l.add(def.FanOut.pos.of("fan_in"), "%v", err)
+		}
+		tasks[def.Name] = t
+		lines[def.Name] = def.pos.of("depends")
+		return t
+	}
+
+	for _, def := range d.Tasks {
+		build(def)
+	}
+	if err := l.err(); err != nil {
+		return nil, err
+	}
+
+	runner := taskflow.NewRunner()
+	for _, def := range d.Tasks {
+		if !dependents[def.Name] {
+			runner.Add(tasks[def.Name])
+		}
+	}
+	if _, err := runner.Graph(); err != nil {
+		for _, err := range unwrap(err) {
+			var mismatch *taskflow.TypeMismatchError
+			if errors.As(err, &mismatch) {
+				l.add(lines[mismatch.Consumer], "task %s cannot receive the output of %s: expected %s, got %s",
+					mismatch.Consumer, mismatch.Producer, mismatch.Input, mismatch.Output)
+				continue
+			}
+			l.add(d.pos.line, "%v", err)
+		}
+		return nil, l.err()
+	}
+
+	runner.WithErrorMode(errorModes[d.ErrorMode]).WithMaxConcurrency(d.MaxConcurrency)
+	for group, n := range d.GroupLimits {
+		runner.WithGroupLimit(group, n)
+	}
+	return runner, nil
+}
+
+// paramsNode returns the params of the task as parsed, or encoded from Params
+// for definitions built by hand.
+func (t *TaskDefinition) paramsNode() *yaml.Node {
+	if t.params != nil || t.Params == nil {
+		return t.params
+	}
+	var node yaml.Node
+	if err := node.Encode(t.Params); err != nil {
+		return nil
+	}
+	return &node
+}
+
+// task returns the definition of the named task.
+func (d *Definition) task(name string) *TaskDefinition {
+	for _, t := range d.Tasks {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// unwrap returns the errors joined in err.
+func unwrap(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
+
+// Load parses the definition in the file at path and builds it with registry.
+func Load(path string, registry *Registry, opts ...Option) (*taskflow.Runner, error) {
+	d, err := ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return d.Build(registry, opts...)
+}
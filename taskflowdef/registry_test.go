@@ -0,0 +1,291 @@
+package taskflowdef_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/josuedeavila/taskflow"
+	"github.com/josuedeavila/taskflow/taskflowdef"
+)
+
+type constantParams struct {
+	Value int `yaml:"value"`
+}
+
+func newRegistry() *taskflowdef.Registry {
+	registry := taskflowdef.NewRegistry()
+	taskflowdef.Register(registry, "fetch", func(ctx context.Context, _ any) ([]int, error) {
+		return []int{1, 2, 3}, nil
+	})
+	taskflowdef.Register(registry, "sum", func(ctx context.Context, n []int) (int, error) {
+		total := 0
+		for _, v := range n {
+			total += v
+		}
+		return total, nil
+	})
+	taskflowdef.Register(registry, "square", func(ctx context.Context, n int) (int, error) {
+		return n * n, nil
+	})
+	taskflowdef.Register(registry, "format", func(ctx context.Context, n int) (string, error) {
+		return strconv.Itoa(n), nil
+	})
+	taskflowdef.RegisterKind(registry, "constant", func(p constantParams) (taskflow.TaskFunc[any, int], error) {
+		if p.Value < 0 {
+			return nil, errors.New("value must not be negative")
+		}
+		return func(ctx context.Context, _ any) (int, error) {
+			return p.Value, nil
+		}, nil
+	})
+	taskflowdef.Register(registry, "concat", func(ctx context.Context, s []string) (string, error) {
+		return "", nil
+	})
+	return registry
+}
+
+func TestBuild(t *testing.T) {
+	d, err := taskflowdef.Parse("orders.yaml", []byte(pipeline))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	runner, err := d.Build(newRegistry(), taskflowdef.WithLogger(taskflow.NoOpLogger{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if runner.ErrorMode != taskflow.ContinueOnError || runner.MaxConcurrency != 4 || runner.GroupLimits["db"] != 1 {
+		t.Errorf("Expected runner settings, got %+v", runner)
+	}
+	if len(runner.Tasks) != 1 {
+		t.Fatalf("Expected only total to be added, got %d tasks", len(runner.Tasks))
+	}
+	fetch := runner.Tasks[0].(*taskflow.Task[[]int, int]).Depends[0].(*taskflow.Task[any, []int])
+	if fetch.Timeout.String() != "5s" || fetch.RetryPolicy.MaxRetries != 3 || fetch.RetryPolicy.Strategy != taskflow.ConstantBackoff {
+		t.Errorf("Expected timeout and retry policy, got %v and %+v", fetch.Timeout, fetch.RetryPolicy)
+	}
+	if !reflect.DeepEqual(fetch.Tags, []string{"db"}) {
+		t.Errorf("Expected tags [db], got %v", fetch.Tags)
+	}
+
+	report, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rec, _ := report.Task("total"); rec.Result != 6 {
+		t.Errorf("Expected 6, got %v", rec.Result)
+	}
+}
+
+func TestBuildFanOut(t *testing.T) {
+	data := `tasks:
+  - name: fetch
+    func: fetch
+  - name: squares
+    depends: [fetch]
+    fan_out:
+      map: square
+      fan_in: sum
+      max_concurrency: 2
+`
+	d, err := taskflowdef.Parse("squares.yaml", []byte(data))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	runner, err := d.Build(newRegistry(), taskflowdef.WithLogger(taskflow.NoOpLogger{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	report, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rec, _ := report.Task("squares"); rec.Result != 14 {
+		t.Errorf("Expected 14, got %v", rec.Result)
+	}
+}
+
+func TestBuildKind(t *testing.T) {
+	data := `tasks:
+  - name: seven
+    func: constant
+    params:
+      value: 7
+  - name: squared
+    func: square
+    depends: [seven]
+`
+	d, err := taskflowdef.Parse("kind.yaml", []byte(data))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	runner, err := d.Build(newRegistry(), taskflowdef.WithLogger(taskflow.NoOpLogger{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	report, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rec, _ := report.Task("squared"); rec.Result != 49 {
+		t.Errorf("Expected 49, got %v", rec.Result)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected []string
+	}{
+		{
+			name: "unknown functions",
+			data: `tasks:
+  - name: a
+    func: fetch
+  - name: b
+    depends: [a]
+    func: missing
+  - name: c
+    fan_out:
+      map: square
+      fan_in: reduce
+`,
+			expected: []string{
+				`wf.yaml:6: unknown function "missing"`,
+				`wf.yaml:10: unknown function "reduce"`,
+			},
+		},
+		{
+			name: "params",
+			data: `tasks:
+  - name: a
+    func: constant
+    params:
+      value: -1
+  - name: b
+    func: constant
+    params:
+      value: 1
+      unit: kg
+  - name: c
+    func: square
+    params: {value: 1}
+`,
+			expected: []string{
+				`wf.yaml:3: constant: value must not be negative`,
+				`wf.yaml:10: unknown param "unit"`,
+				`wf.yaml:12: square: params only apply to task kinds`,
+			},
+		},
+		{
+			name: "type mismatch",
+			data: `tasks:
+  - name: fetch
+    func: fetch
+  - name: format
+    func: format
+    depends: [fetch]
+`,
+			expected: []string{
+				`wf.yaml:6: task format cannot receive the output of fetch: expected int, got []int`,
+			},
+		},
+		{
+			name: "fan-in mismatch",
+			data: `tasks:
+  - name: squares
+    fan_out:
+      map: square
+      fan_in: concat
+`,
+			expected: []string{
+				`wf.yaml:5: fan_in function "concat" is func(context.Context, []string) (string, error), expected func(context.Context, []int) (int, error) to combine the results of "square"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := taskflowdef.Parse("wf.yaml", []byte(tt.data))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			_, err = d.Build(newRegistry())
+			assertErrors(t, err, tt.expected...)
+		})
+	}
+}
+
+func TestBuildByHand(t *testing.T) {
+	d := &taskflowdef.Definition{Tasks: []*taskflowdef.TaskDefinition{
+		{Name: "seven", Func: "constant", Params: map[string]any{"value": 7}},
+		{Name: "squared", Func: "square", Depends: []string{"seven"}},
+	}}
+	runner, err := d.Build(newRegistry(), taskflowdef.WithLogger(taskflow.NoOpLogger{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	report, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rec, _ := report.Task("squared"); rec.Result != 49 {
+		t.Errorf("Expected 49, got %v", rec.Result)
+	}
+
+	tests := []struct {
+		name     string
+		tasks    []*taskflowdef.TaskDefinition
+		expected []string
+	}{
+		{
+			name:     "missing dependency",
+			tasks:    []*taskflowdef.TaskDefinition{{Name: "a", Func: "fetch", Depends: []string{"missing"}}},
+			expected: []string{`task "a" depends on undefined task "missing"`},
+		},
+		{
+			name: "cycle",
+			tasks: []*taskflowdef.TaskDefinition{
+				{Name: "a", Func: "square", Depends: []string{"b"}},
+				{Name: "b", Func: "square", Depends: []string{"a"}},
+			},
+			expected: []string{"dependency cycle: a -> b -> a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &taskflowdef.Definition{Tasks: tt.tasks}
+			_, err := d.Build(newRegistry())
+			assertErrors(t, err, tt.expected...)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.yaml")
+	if err := os.WriteFile(path, []byte(pipeline), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := taskflowdef.Load(path, newRegistry()); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if _, err := taskflowdef.Load(filepath.Join(t.TempDir(), "missing.yaml"), newRegistry()); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected os.ErrNotExist, got %v", err)
+	}
+}
+
+func TestRegistryNames(t *testing.T) {
+	expected := []string{"concat", "constant", "fetch", "format", "square", "sum"}
+	if names := newRegistry().Names(); !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected %v, got %v", expected, names)
+	}
+}
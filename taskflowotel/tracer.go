@@ -0,0 +1,197 @@
+// Package taskflowotel traces taskflow runs with OpenTelemetry.
+//
+// A Tracer is a taskflow.Observer that starts a span for each run, a child span
+// for each task, and, within a task, a span for each attempt and for each
+// function of a fan-out. Task spans link to the spans of their dependencies.
+//
+//	runner := taskflow.NewRunner().WithObserver(taskflowotel.NewTracer())
+package taskflowotel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/josuedeavila/taskflow"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the spans.
+const ScopeName = "github.com/josuedeavila/taskflow/taskflowotel"
+
+// Span attribute keys.
+const (
+	RunIDKey      = attribute.Key("taskflow.run.id")
+	TaskKey       = attribute.Key("taskflow.task.name")
+	StatusKey     = attribute.Key("taskflow.task.status")
+	InputTypeKey  = attribute.Key("taskflow.task.input_type")
+	OutputTypeKey = attribute.Key("taskflow.task.output_type")
+	AttemptKey    = attribute.Key("taskflow.attempt")
+	AttemptsKey   = attribute.Key("taskflow.task.attempts")
+	IndexKey      = attribute.Key("taskflow.fanout.index")
+	WidthKey      = attribute.Key("taskflow.fanout.width")
+	DelayKey      = attribute.Key("taskflow.retry.delay")
+)
+
+// Tracer creates spans from taskflow events. Add it to a Runner, or to a single
+// Task, with WithObserver.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// Option configures a Tracer.
+type Option func(*Tracer)
+
+// WithTracerProvider sets the provider of the tracer, otel.GetTracerProvider() by default.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(t *Tracer) {
+		t.tracer = tp.Tracer(ScopeName)
+	}
+}
+
+// NewTracer returns a Tracer using the global tracer provider, unless
+// WithTracerProvider is given.
+func NewTracer(opts ...Option) *Tracer {
+	t := &Tracer{}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if t.tracer == nil {
+		t.tracer = otel.GetTracerProvider().Tracer(ScopeName)
+	}
+	return t
+}
+
+// spanKey is the context key of the innermost span started by a Tracer.
+type spanKey struct{}
+
+// span is a span started by a Tracer, with what identifies the event that ends it.
+type span struct {
+	trace.Span
+	start   taskflow.EventType
+	runID   string
+	task    string
+	attempt int
+	index   int
+}
+
+// StartContext starts a span for the run, task, attempt or fan-out function the
+// event starts, and returns a context carrying it.
+func (t *Tracer) StartContext(ctx context.Context, e taskflow.Event) context.Context {
+	var name string
+	opts := []trace.SpanStartOption{
+		trace.WithTimestamp(e.Time),
+		trace.WithAttributes(RunIDKey.String(e.RunID)),
+	}
+	if e.Task != "" {
+		opts = append(opts, trace.WithAttributes(TaskKey.String(e.Task)))
+	}
+
+	switch e.Type {
+	case taskflow.EventRunStarted:
+		name = "taskflow.run"
+	case taskflow.EventTaskStarted:
+		name = "taskflow.task " + e.Task
+		opts = append(opts, trace.WithAttributes(typeAttributes(e)...))
+		for _, dep := range e.DependencyContexts {
+			if sc := trace.SpanContextFromContext(dep); sc.IsValid() {
+				opts = append(opts, trace.WithLinks(trace.Link{SpanContext: sc}))
+			}
+		}
+	case taskflow.EventAttemptStarted:
+		name = fmt.Sprintf("taskflow.attempt %s", e.Task)
+		opts = append(opts, trace.WithAttributes(AttemptKey.Int(e.Attempt)))
+	case taskflow.EventFanOutItemStarted:
+		name = fmt.Sprintf("taskflow.fanout %s", e.Task)
+		opts = append(opts, trace.WithAttributes(IndexKey.Int(e.Index)))
+	default:
+		return ctx
+	}
+
+	ctx, s := t.tracer.Start(ctx, name, opts...)
+	return context.WithValue(ctx, spanKey{}, &span{
+		Span:    s,
+		start:   e.Type,
+		runID:   e.RunID,
+		task:    e.Task,
+		attempt: e.Attempt,
+		index:   e.Index,
+	})
+}
+
+// Observe ends the span of a finished run, task, attempt or fan-out function,
+// and records retries and fan-out widths on the current span.
+func (t *Tracer) Observe(ctx context.Context, e taskflow.Event) {
+	switch e.Type {
+	case taskflow.EventRunFinished:
+		t.end(ctx, e, taskflow.EventRunStarted)
+	case taskflow.EventAttemptFinished:
+		t.end(ctx, e, taskflow.EventAttemptStarted)
+	case taskflow.EventFanOutItemFinished:
+		t.end(ctx, e, taskflow.EventFanOutItemStarted)
+	case taskflow.EventTaskSucceeded, taskflow.EventTaskFailed, taskflow.EventTaskCancelled, taskflow.EventTaskSkipped:
+		t.end(ctx, e, taskflow.EventTaskStarted)
+	case taskflow.EventTaskRetrying:
+		trace.SpanFromContext(ctx).AddEvent("retry", trace.WithTimestamp(e.Time), trace.WithAttributes(
+			AttemptKey.Int(e.Attempt),
+			DelayKey.String(e.Delay.String()),
+			attribute.String("exception.message", e.Err.Error()),
+		))
+	case taskflow.EventFanOutStarted:
+		trace.SpanFromContext(ctx).SetAttributes(WidthKey.Int(e.Width))
+	}
+}
+
+// end ends the span started by the start event matching e. Tasks that finish
+// without having started, such as skipped ones, get a span of their own.
+func (t *Tracer) end(ctx context.Context, e taskflow.Event, start taskflow.EventType) {
+	s, ok := ctx.Value(spanKey{}).(*span)
+	if !ok || !s.matches(e, start) {
+		if start != taskflow.EventTaskStarted {
+			return
+		}
+		_, ts := t.tracer.Start(ctx, "taskflow.task "+e.Task,
+			trace.WithTimestamp(e.Time),
+			trace.WithAttributes(RunIDKey.String(e.RunID), TaskKey.String(e.Task)),
+			trace.WithAttributes(typeAttributes(e)...),
+		)
+		s = &span{Span: ts}
+	}
+
+	if start == taskflow.EventTaskStarted {
+		s.SetAttributes(StatusKey.String(string(e.Status())), AttemptsKey.Int(e.Attempt))
+	}
+	if e.Err != nil {
+		s.RecordError(e.Err, trace.WithTimestamp(e.Time))
+		s.SetStatus(codes.Error, e.Err.Error())
+	}
+	s.End(trace.WithTimestamp(e.Time))
+}
+
+// matches reports whether e finishes what s was started for.
+func (s *span) matches(e taskflow.Event, start taskflow.EventType) bool {
+	if s.start != start || s.runID != e.RunID || s.task != e.Task {
+		return false
+	}
+	switch start {
+	case taskflow.EventAttemptStarted:
+		return s.attempt == e.Attempt
+	case taskflow.EventFanOutItemStarted:
+		return s.index == e.Index
+	}
+	return true
+}
+
+// typeAttributes returns the input and output types of the event's task.
+func typeAttributes(e taskflow.Event) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if e.InputType != nil {
+		attrs = append(attrs, InputTypeKey.String(e.InputType.String()))
+	}
+	if e.OutputType != nil {
+		attrs = append(attrs, OutputTypeKey.String(e.OutputType.String()))
+	}
+	return attrs
+}
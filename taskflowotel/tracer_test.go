@@ -0,0 +1,230 @@
+package taskflowotel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/josuedeavila/taskflow"
+	"github.com/josuedeavila/taskflow/taskflowotel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTracer() (*taskflowotel.Tracer, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	return taskflowotel.NewTracer(taskflowotel.WithTracerProvider(tp)), exporter
+}
+
+// spansByName indexes the exported spans by name. Names must be unique.
+func spansByName(t *testing.T, spans tracetest.SpanStubs) map[string]tracetest.SpanStub {
+	t.Helper()
+	byName := make(map[string]tracetest.SpanStub)
+	for _, s := range spans {
+		if _, ok := byName[s.Name]; ok {
+			t.Fatalf("Expected a single span named %s", s.Name)
+		}
+		byName[s.Name] = s
+	}
+	return byName
+}
+
+func TestTracer(t *testing.T) {
+	tracer, exporter := newTracer()
+
+	var taskSpan trace.SpanContext
+	fetch := taskflow.NewTask("fetch", func(ctx context.Context, _ any) (string, error) {
+		taskSpan = trace.SpanContextFromContext(ctx)
+		return "data", nil
+	}).WithLogger(taskflow.NoOpLogger{})
+
+	calls := 0
+	process := taskflow.NewTask("process", func(ctx context.Context, input string) (string, error) {
+		calls++
+		if calls == 1 {
+			return "", errors.New("temporary failure")
+		}
+		return "processed_" + input, nil
+	}).WithRetry(taskflow.RetryPolicy{
+		MaxRetries: 1,
+		Strategy:   taskflow.ConstantBackoff,
+		BaseDelay:  time.Millisecond,
+	}).WithLogger(taskflow.NoOpLogger{}).After(fetch)
+
+	runner := taskflow.NewRunner().WithObserver(tracer)
+	runner.Add(process)
+
+	report, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	// run, 2 tasks, 3 attempts
+	if len(spans) != 6 {
+		t.Fatalf("Expected 6 spans, got %d", len(spans))
+	}
+
+	var run, fetchSpan, processSpan tracetest.SpanStub
+	var attempts []tracetest.SpanStub
+	for _, s := range spans {
+		switch s.Name {
+		case "taskflow.run":
+			run = s
+		case "taskflow.task fetch":
+			fetchSpan = s
+		case "taskflow.task process":
+			processSpan = s
+		default:
+			attempts = append(attempts, s)
+		}
+	}
+
+	for _, s := range []tracetest.SpanStub{fetchSpan, processSpan} {
+		if s.Parent.SpanID() != run.SpanContext.SpanID() {
+			t.Errorf("Expected %s to be a child of the run span", s.Name)
+		}
+	}
+	for _, s := range attempts {
+		if s.Parent.SpanID() != fetchSpan.SpanContext.SpanID() && s.Parent.SpanID() != processSpan.SpanContext.SpanID() {
+			t.Errorf("Expected %s to be a child of a task span", s.Name)
+		}
+	}
+
+	if taskSpan.SpanID() == fetchSpan.SpanContext.SpanID() || taskSpan.TraceID() != run.SpanContext.TraceID() {
+		t.Errorf("Expected the task function to run within its attempt span")
+	}
+
+	if len(processSpan.Links) != 1 || processSpan.Links[0].SpanContext.SpanID() != fetchSpan.SpanContext.SpanID() {
+		t.Errorf("Expected process to link to fetch, got %v", processSpan.Links)
+	}
+
+	if len(processSpan.Events) != 1 || processSpan.Events[0].Name != "retry" {
+		t.Errorf("Expected a retry event on process, got %v", processSpan.Events)
+	}
+
+	attrs := make(map[string]string)
+	for _, kv := range processSpan.Attributes {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	expected := map[string]string{
+		"taskflow.run.id":           report.RunID,
+		"taskflow.task.name":        "process",
+		"taskflow.task.status":      "succeeded",
+		"taskflow.task.attempts":    "2",
+		"taskflow.task.input_type":  "string",
+		"taskflow.task.output_type": "string",
+	}
+	for k, v := range expected {
+		if attrs[k] != v {
+			t.Errorf("Expected attribute %s=%s, got %s", k, v, attrs[k])
+		}
+	}
+
+	failedAttempts := 0
+	for _, s := range attempts {
+		if s.Status.Code == codes.Error {
+			failedAttempts++
+		}
+	}
+	if failedAttempts != 1 {
+		t.Errorf("Expected 1 failed attempt, got %d", failedAttempts)
+	}
+}
+
+func TestTracerFanOut(t *testing.T) {
+	tracer, exporter := newTracer()
+
+	fan := &taskflow.FanOutTask[int, int]{
+		Name: "square",
+		Map: func(ctx context.Context, input int) (int, error) {
+			if input < 0 {
+				return 0, errors.New("negative input")
+			}
+			return input * input, nil
+		},
+		FanInResults: func(ctx context.Context, results []taskflow.Result[int]) (int, error) {
+			return len(results), nil
+		},
+	}
+
+	task := fan.ToTask().WithLogger(taskflow.NoOpLogger{}).WithObserver(tracer)
+	if _, err := task.Run(context.Background(), []int{1, -2, 3}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var attempt tracetest.SpanStub
+	var items []tracetest.SpanStub
+	for _, s := range exporter.GetSpans() {
+		switch s.Name {
+		case "taskflow.attempt square":
+			attempt = s
+		case "taskflow.fanout square":
+			items = append(items, s)
+		}
+	}
+
+	if len(items) != 3 {
+		t.Fatalf("Expected 3 fan-out spans, got %d", len(items))
+	}
+	failed := 0
+	for _, s := range items {
+		if s.Parent.SpanID() != attempt.SpanContext.SpanID() {
+			t.Errorf("Expected fan-out span to be a child of the attempt span")
+		}
+		if s.Status.Code == codes.Error {
+			failed++
+		}
+	}
+	if failed != 1 {
+		t.Errorf("Expected 1 failed fan-out span, got %d", failed)
+	}
+
+	width := false
+	for _, kv := range attempt.Attributes {
+		if kv.Key == taskflowotel.WidthKey && kv.Value.AsInt64() == 3 {
+			width = true
+		}
+	}
+	if !width {
+		t.Errorf("Expected fan-out width 3 on the attempt span, got %v", attempt.Attributes)
+	}
+}
+
+func TestTracerSkippedTask(t *testing.T) {
+	tracer, exporter := newTracer()
+
+	failing := taskflow.NewTask("failing", func(ctx context.Context, _ any) (any, error) {
+		return nil, errors.New("failure")
+	}).WithLogger(taskflow.NoOpLogger{})
+	dependent := taskflow.NewTask("dependent", func(ctx context.Context, _ any) (any, error) {
+		return nil, nil
+	}).WithLogger(taskflow.NoOpLogger{}).After(failing)
+
+	runner := taskflow.NewRunner().WithObserver(tracer)
+	runner.Add(dependent)
+	_, _ = runner.Run(context.Background())
+
+	spans := spansByName(t, exporter.GetSpans())
+	run := spans["taskflow.run"]
+	if run.Status.Code != codes.Error {
+		t.Errorf("Expected the run span to record the error, got %v", run.Status)
+	}
+
+	skipped, ok := spans["taskflow.task dependent"]
+	if !ok {
+		t.Fatal("Expected a span for the skipped task")
+	}
+	if skipped.Parent.SpanID() != run.SpanContext.SpanID() {
+		t.Error("Expected the skipped task span to be a child of the run span")
+	}
+	for _, kv := range skipped.Attributes {
+		if kv.Key == taskflowotel.StatusKey && kv.Value.AsString() != "skipped" {
+			t.Errorf("Expected status skipped, got %s", kv.Value.AsString())
+		}
+	}
+}
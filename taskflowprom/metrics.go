@@ -0,0 +1,158 @@
+// Package taskflowprom exposes Prometheus metrics about taskflow runs.
+//
+// Metrics is a taskflow.Observer and a prometheus.Collector: register it and
+// add it to the runners and tasks to measure.
+//
+//	metrics := taskflowprom.NewMetrics()
+//	prometheus.MustRegister(metrics)
+//	runner := taskflow.NewRunner().WithObserver(metrics)
+package taskflowprom
+
+import (
+	"context"
+
+	"github.com/josuedeavila/taskflow"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics records task executions, durations, retries, fan-out widths, queue
+// wait times and in-flight tasks, labelled by task name.
+type Metrics struct {
+	executions *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+	retries    *prometheus.CounterVec
+	fanOut     *prometheus.HistogramVec
+	queueWait  *prometheus.HistogramVec
+	inFlight   *prometheus.GaugeVec
+	runs       *prometheus.CounterVec
+	runTime    prometheus.Histogram
+}
+
+// options holds the settings of Metrics.
+type options struct {
+	namespace string
+	buckets   []float64
+}
+
+// Option configures Metrics.
+type Option func(*options)
+
+// WithNamespace sets the namespace of the metric names, "taskflow" by default.
+func WithNamespace(namespace string) Option {
+	return func(o *options) {
+		o.namespace = namespace
+	}
+}
+
+// WithBuckets sets the buckets, in seconds, of the duration and queue wait
+// histograms, prometheus.DefBuckets by default.
+func WithBuckets(buckets []float64) Option {
+	return func(o *options) {
+		o.buckets = buckets
+	}
+}
+
+// NewMetrics returns unregistered metrics.
+func NewMetrics(opts ...Option) *Metrics {
+	o := options{namespace: "taskflow", buckets: prometheus.DefBuckets}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &Metrics{
+		executions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.namespace,
+			Name:      "task_executions_total",
+			Help:      "Number of completed task executions by task and status.",
+		}, []string{"task", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: o.namespace,
+			Name:      "task_duration_seconds",
+			Help:      "Duration of task executions, including retries, by task and status.",
+			Buckets:   o.buckets,
+		}, []string{"task", "status"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.namespace,
+			Name:      "task_retries_total",
+			Help:      "Number of failed attempts that were retried, by task.",
+		}, []string{"task"}),
+		fanOut: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: o.namespace,
+			Name:      "fanout_width",
+			Help:      "Number of functions run by fan-out tasks.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+		}, []string{"task"}),
+		queueWait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: o.namespace,
+			Name:      "task_queue_wait_seconds",
+			Help:      "Time tasks waited for a worker once their dependencies completed.",
+			Buckets:   o.buckets,
+		}, []string{"task"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: o.namespace,
+			Name:      "tasks_in_flight",
+			Help:      "Number of task functions currently running, by task.",
+		}, []string{"task"}),
+		runs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.namespace,
+			Name:      "runs_total",
+			Help:      "Number of completed runs by status.",
+		}, []string{"status"}),
+		runTime: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: o.namespace,
+			Name:      "run_duration_seconds",
+			Help:      "Duration of runs.",
+			Buckets:   o.buckets,
+		}),
+	}
+}
+
+// Observe records the event.
+func (m *Metrics) Observe(ctx context.Context, e taskflow.Event) {
+	switch e.Type {
+	case taskflow.EventTaskStarted:
+		m.queueWait.WithLabelValues(e.Task).Observe(e.QueueWait.Seconds())
+	case taskflow.EventAttemptStarted:
+		m.inFlight.WithLabelValues(e.Task).Inc()
+	case taskflow.EventAttemptFinished:
+		m.inFlight.WithLabelValues(e.Task).Dec()
+	case taskflow.EventTaskRetrying:
+		m.retries.WithLabelValues(e.Task).Inc()
+	case taskflow.EventFanOutStarted:
+		m.fanOut.WithLabelValues(e.Task).Observe(float64(e.Width))
+	case taskflow.EventTaskSucceeded, taskflow.EventTaskFailed, taskflow.EventTaskSkipped, taskflow.EventTaskCancelled:
+		status := string(e.Status())
+		m.executions.WithLabelValues(e.Task, status).Inc()
+		if e.Type != taskflow.EventTaskSkipped {
+			m.duration.WithLabelValues(e.Task, status).Observe(e.Duration.Seconds())
+		}
+	case taskflow.EventRunFinished:
+		status := "succeeded"
+		if e.Err != nil {
+			status = "failed"
+		}
+		m.runs.WithLabelValues(status).Inc()
+		m.runTime.Observe(e.Duration.Seconds())
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range m.collectors() {
+		c.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range m.collectors() {
+		c.Collect(ch)
+	}
+}
+
+func (m *Metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.executions, m.duration, m.retries, m.fanOut,
+		m.queueWait, m.inFlight, m.runs, m.runTime,
+	}
+}
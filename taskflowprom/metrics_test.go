@@ -0,0 +1,138 @@
+package taskflowprom_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josuedeavila/taskflow"
+	"github.com/josuedeavila/taskflow/taskflowprom"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	metrics := taskflowprom.NewMetrics()
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(metrics)
+
+	calls := 0
+	flaky := taskflow.NewTask("flaky", func(ctx context.Context, _ any) ([]int, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("temporary failure")
+		}
+		return []int{1, 2, 3}, nil
+	}).WithRetry(taskflow.RetryPolicy{
+		MaxRetries: 1,
+		Strategy:   taskflow.ConstantBackoff,
+		BaseDelay:  time.Millisecond,
+	}).WithLogger(taskflow.NoOpLogger{})
+
+	fan := &taskflow.FanOutTask[int, int]{
+		Name: "square",
+		Map: func(ctx context.Context, input int) (int, error) {
+			return input * input, nil
+		},
+		FanIn: func(ctx context.Context, results []int) (int, error) {
+			return 0, errors.New("fan-in failure")
+		},
+	}
+	square := fan.ToTask().WithLogger(taskflow.NoOpLogger{}).After(flaky)
+
+	store := taskflow.NewTask("store", func(ctx context.Context, _ int) (any, error) {
+		return nil, nil
+	}).WithLogger(taskflow.NoOpLogger{}).After(square)
+
+	runner := taskflow.NewRunner().WithObserver(metrics)
+	runner.Add(store)
+	if _, err := runner.Run(context.Background()); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	expected := `
+# HELP taskflow_task_executions_total Number of completed task executions by task and status.
+# TYPE taskflow_task_executions_total counter
+taskflow_task_executions_total{status="failed",task="square"} 1
+taskflow_task_executions_total{status="skipped",task="store"} 1
+taskflow_task_executions_total{status="succeeded",task="flaky"} 1
+# HELP taskflow_task_retries_total Number of failed attempts that were retried, by task.
+# TYPE taskflow_task_retries_total counter
+taskflow_task_retries_total{task="flaky"} 1
+# HELP taskflow_runs_total Number of completed runs by status.
+# TYPE taskflow_runs_total counter
+taskflow_runs_total{status="failed"} 1
+# HELP taskflow_tasks_in_flight Number of task functions currently running, by task.
+# TYPE taskflow_tasks_in_flight gauge
+taskflow_tasks_in_flight{task="flaky"} 0
+taskflow_tasks_in_flight{task="square"} 0
+`
+	names := []string{
+		"taskflow_task_executions_total",
+		"taskflow_task_retries_total",
+		"taskflow_runs_total",
+		"taskflow_tasks_in_flight",
+	}
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), names...); err != nil {
+		t.Error(err)
+	}
+
+	counts := map[string]int{
+		"taskflow_task_duration_seconds":   2, // flaky and square, skipped tasks don't run
+		"taskflow_task_queue_wait_seconds": 2,
+		"taskflow_fanout_width":            1,
+		"taskflow_run_duration_seconds":    1,
+	}
+	for name, expected := range counts {
+		count, err := testutil.GatherAndCount(registry, name)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if count != expected {
+			t.Errorf("Expected %d series of %s, got %d", expected, name, count)
+		}
+	}
+}
+
+func TestMetricsFanOutWidth(t *testing.T) {
+	metrics := taskflowprom.NewMetrics(taskflowprom.WithNamespace("pipeline"))
+
+	fan := &taskflow.FanOutTask[int, int]{
+		Name: "square",
+		Map: func(ctx context.Context, input int) (int, error) {
+			return input * input, nil
+		},
+		FanIn: func(ctx context.Context, results []int) (int, error) {
+			return len(results), nil
+		},
+	}
+	task := fan.ToTask().WithLogger(taskflow.NoOpLogger{}).WithObserver(metrics)
+	if _, err := task.Run(context.Background(), []int{1, 2, 3, 4, 5}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `
+# HELP pipeline_fanout_width Number of functions run by fan-out tasks.
+# TYPE pipeline_fanout_width histogram
+pipeline_fanout_width_bucket{task="square",le="1"} 0
+pipeline_fanout_width_bucket{task="square",le="2"} 0
+pipeline_fanout_width_bucket{task="square",le="4"} 0
+pipeline_fanout_width_bucket{task="square",le="8"} 1
+pipeline_fanout_width_bucket{task="square",le="16"} 1
+pipeline_fanout_width_bucket{task="square",le="32"} 1
+pipeline_fanout_width_bucket{task="square",le="64"} 1
+pipeline_fanout_width_bucket{task="square",le="128"} 1
+pipeline_fanout_width_bucket{task="square",le="256"} 1
+pipeline_fanout_width_bucket{task="square",le="512"} 1
+pipeline_fanout_width_bucket{task="square",le="1024"} 1
+pipeline_fanout_width_bucket{task="square",le="2048"} 1
+pipeline_fanout_width_bucket{task="square",le="+Inf"} 1
+pipeline_fanout_width_sum{task="square"} 5
+pipeline_fanout_width_count{task="square"} 1
+`
+	if err := testutil.CollectAndCompare(metrics, strings.NewReader(expected), "pipeline_fanout_width"); err != nil {
+		t.Error(err)
+	}
+}
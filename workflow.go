@@ -0,0 +1,404 @@
+package taskflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Workflow is a validated, reusable definition of a task graph together with
+// the settings to run it, created with Runner.Workflow or NewWorkflow.
+// Every call to Run starts an independent execution with its own results and
+// errors, so the same workflow can run many times, even concurrently.
+type Workflow struct {
+	graph          *Graph
+	errorMode      ErrorMode
+	maxConcurrency int
+	groupLimits    map[string]int
+	observers      []Observer
+	store          StateStore
+}
+
+// NewWorkflow validates the given tasks and returns a workflow with the default
+// Runner settings.
+func NewWorkflow(tasks ...Executable) (*Workflow, error) {
+	r := NewRunner()
+	r.Add(tasks...)
+	return r.Workflow()
+}
+
+// Graph returns the workflow's dependency graph.
+func (w *Workflow) Graph() *Graph {
+	return w.graph
+}
+
+// Run executes the workflow and returns a report with the outcome of every task.
+// Tasks without dependencies receive input as their input.
+// A task is handed to a worker once all of its dependencies have completed, within
+// the limits set by MaxConcurrency and GroupLimits. Dependencies of sequential
+// tasks run inside the task that chains them, and tasks whose dependencies failed
+// are skipped.
+// In FailFast mode the first error cancels the context passed to every task;
+// in ContinueOnError mode the remaining tasks run to completion.
+// The returned error joins the errors of all failed tasks, see RunReport.Err.
+// With a StateStore, the outcome of each task is persisted as soon as it completes
+// and the run can be resumed with Resume, using the report's RunID; every task
+// must then have a unique name.
+func (w *Workflow) Run(ctx context.Context, input any) (*RunReport, error) {
+	x := w.newExecution()
+	if w.store != nil {
+		if err := w.checkNames(); err != nil {
+			return nil, err
+		}
+		x.store = w.store
+		if err := w.store.CreateRun(ctx, x.id); err != nil {
+			return nil, err
+		}
+	}
+	return w.run(ctx, x, input)
+}
+
+// Resume continues a run interrupted before all of its tasks succeeded, using
+// the states persisted to the workflow's StateStore. Tasks that succeeded are
+// not run again: their stored results are decoded and fed to their dependents,
+// and they are marked as Restored in the report, as are the dependencies a
+// restored SequentialDependencies task would run itself. The other tasks run
+// as in Run, with the given input, and their outcome is persisted under the
+// same run ID, after the store is told the run started again with CreateRun.
+// Results are stored as JSON, so they must survive a round trip through
+// encoding/json to be restored; tasks whose result can't be encoded, such as a
+// func or a channel, still succeed but run again on resume, as do tasks whose
+// result can't be decoded.
+// Saved states are matched to tasks by name, so every task needs a unique name.
+func (w *Workflow) Resume(ctx context.Context, runID string, input any) (*RunReport, error) {
+	if w.store == nil {
+		return nil, errors.New("taskflow: resume requires a state store")
+	}
+	if err := w.checkNames(); err != nil {
+		return nil, err
+	}
+	states, err := w.store.LoadTasks(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.store.CreateRun(ctx, runID); err != nil {
+		return nil, err
+	}
+
+	x := w.newExecution()
+	x.id = runID
+	x.store = w.store
+	x.restored = make(map[string]TaskState, len(states))
+	for _, state := range states {
+		x.restored[state.Task] = state
+	}
+	return w.run(ctx, x, input)
+}
+
+// checkNames reports the tasks without a name, whose state couldn't be told
+// apart from others in the workflow's StateStore. Named tasks are unique, see
+// DuplicateNameError.
+func (w *Workflow) checkNames() error {
+	var errs []error
+	for _, n := range w.graph.Nodes() {
+		if node, ok := n.(Node); !ok || node.GetName() == "" {
+			errs = append(errs, fmt.Errorf("taskflow: state store requires named tasks, %s has no name", nodeName(n)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// newExecution returns an execution notifying the workflow's observers, and its
+// state store if it is an Observer too.
+func (w *Workflow) newExecution() *execution {
+	observers := w.observers
+	if o, ok := w.store.(Observer); ok {
+		observers = append(observers[:len(observers):len(observers)], o)
+	}
+	return newExecution(observers...)
+}
+
+// run executes the workflow within x.
+func (w *Workflow) run(ctx context.Context, x *execution, input any) (*RunReport, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	ctx = withExecution(ctx, x)
+	start := time.Now()
+	ctx = x.start(ctx, nil, Event{Type: EventRunStarted, Time: start})
+
+	s := newSchedule(w.graph)
+	workers := w.maxConcurrency
+	if workers <= 0 || workers > len(s.nodes) {
+		workers = len(s.nodes)
+	}
+
+	type result struct {
+		task Executable
+		err  error
+	}
+	jobs := make(chan Executable, workers)
+	results := make(chan result, workers)
+	defer close(jobs)
+
+	for range workers {
+		go func() {
+			for t := range jobs {
+				_, err := x.run(ctx, t, input)
+				results <- result{task: t, err: err}
+			}
+		}()
+	}
+
+	running := 0
+	groups := make(map[string]int)
+
+	for {
+		for ctx.Err() == nil && running < workers {
+			t := s.next(func(t Executable) string { return w.blockingGroup(t, groups) })
+			if t == nil {
+				break
+			}
+			for _, tag := range tagsOf(t) {
+				groups[tag]++
+			}
+			running++
+			x.enqueue(t, s.readyAt[t])
+			jobs <- t
+		}
+
+		if running == 0 {
+			break
+		}
+
+		res := <-results
+		running--
+		for _, tag := range tagsOf(res.task) {
+			groups[tag]--
+			s.unblock(tag)
+		}
+
+		if res.err != nil && w.errorMode == FailFast {
+			cancel(res.err)
+		}
+		s.complete(res.task, res.err != nil)
+	}
+
+	x.skipUnstarted(ctx, w.graph)
+	report := x.report(w.graph, start, time.Now())
+	err := report.Err()
+	if err == nil && (s.remaining > 0 || !report.Succeeded()) {
+		// Nothing failed on its own, but the parent context was cancelled
+		// before every task could complete.
+		err = ctx.Err()
+	}
+
+	x.emit(ctx, nil, Event{
+		Type:     EventRunFinished,
+		Time:     report.End,
+		Start:    start,
+		Duration: report.Duration,
+		Err:      err,
+		Report:   report,
+	})
+	return report, err
+}
+
+// blockingGroup returns a group that t belongs to whose limit is reached, or ""
+// if t can start.
+func (w *Workflow) blockingGroup(t Executable, running map[string]int) string {
+	for _, tag := range tagsOf(t) {
+		if limit, ok := w.groupLimits[tag]; ok && running[tag] >= limit {
+			return tag
+		}
+	}
+	return ""
+}
+
+// schedule tracks which tasks of a graph are ready to be handed to a worker.
+type schedule struct {
+	nodes      []Executable
+	pending    map[Executable]int // Number of dependencies not yet completed
+	failed     map[Executable]bool
+	dependents map[Executable][]Executable
+	ready      queue
+	waiting    map[string]*queue        // Ready tasks held back by a group limit
+	unblocked  []string                 // Groups with waiting tasks that have freed up
+	readyAt    map[Executable]time.Time // When each task became ready
+	remaining  int                      // Tasks not yet completed
+}
+
+// newSchedule prepares the tasks of g that the runner executes itself. Tasks only
+// reachable through the dependencies of a sequential task are left to that task.
+func newSchedule(g *Graph) *schedule {
+	s := &schedule{
+		pending:    make(map[Executable]int),
+		failed:     make(map[Executable]bool),
+		dependents: make(map[Executable][]Executable),
+		waiting:    make(map[string]*queue),
+		readyAt:    make(map[Executable]time.Time),
+	}
+
+	scheduled := make(map[Executable]bool)
+	var visit func(e Executable)
+	visit = func(e Executable) {
+		if scheduled[e] {
+			return
+		}
+		scheduled[e] = true
+		if inlinesDependencies(e) {
+			return
+		}
+		for _, dep := range g.Dependencies(e) {
+			visit(dep)
+		}
+	}
+	for _, root := range g.Roots() {
+		visit(root)
+	}
+
+	// Graph nodes are in topological order, so dependencies are added first.
+	for _, n := range g.Nodes() {
+		if !scheduled[n] {
+			continue
+		}
+		s.nodes = append(s.nodes, n)
+		if !inlinesDependencies(n) {
+			for _, dep := range g.Dependencies(n) {
+				s.pending[n]++
+				s.dependents[dep] = append(s.dependents[dep], n)
+			}
+		}
+		if s.pending[n] == 0 {
+			s.markReady(n)
+		}
+	}
+	s.remaining = len(s.nodes)
+
+	return s
+}
+
+// markReady queues t to be handed to a worker.
+func (s *schedule) markReady(t Executable) {
+	s.ready.push(t)
+	s.readyAt[t] = time.Now()
+}
+
+// next removes and returns the next ready task that isn't blocked, or nil if
+// there is none. blocking returns the group whose limit keeps a task from
+// starting, if any; blocked tasks wait in a queue for that group, so that
+// each task is only looked at again once its group frees up. Tasks that waited
+// on a group go first, in the order they became ready.
+func (s *schedule) next(blocking func(Executable) string) Executable {
+	for len(s.unblocked) > 0 {
+		group := s.unblocked[0]
+		q := s.waiting[group]
+		if q.len() == 0 {
+			s.unblocked = s.unblocked[1:]
+			continue
+		}
+		t := q.peek()
+		switch b := blocking(t); b {
+		case "":
+			return q.pop()
+		case group:
+			s.unblocked = s.unblocked[1:]
+		default:
+			s.wait(b, q.pop())
+		}
+	}
+	for s.ready.len() > 0 {
+		t := s.ready.pop()
+		if b := blocking(t); b != "" {
+			s.wait(b, t)
+			continue
+		}
+		return t
+	}
+	return nil
+}
+
+// wait holds t back until group frees up.
+func (s *schedule) wait(group string, t Executable) {
+	q, ok := s.waiting[group]
+	if !ok {
+		q = &queue{}
+		s.waiting[group] = q
+	}
+	q.push(t)
+}
+
+// unblock tells next to look at the tasks waiting on group again, after one of
+// its tasks completed.
+func (s *schedule) unblock(group string) {
+	if q, ok := s.waiting[group]; ok && q.len() > 0 {
+		s.unblocked = append(s.unblocked, group)
+	}
+}
+
+// complete marks t as done and queues the dependents it unblocks. Dependents of
+// a failed task are not run; they complete as failed as well.
+func (s *schedule) complete(t Executable, failed bool) {
+	s.remaining--
+	for _, d := range s.dependents[t] {
+		if failed {
+			s.failed[d] = true
+		}
+		s.pending[d]--
+		if s.pending[d] > 0 {
+			continue
+		}
+		if s.failed[d] {
+			s.complete(d, true)
+			continue
+		}
+		s.markReady(d)
+	}
+}
+
+// queue is a first-in, first-out queue of tasks.
+type queue struct {
+	tasks []Executable
+	head  int // Index of the first task in tasks
+}
+
+func (q *queue) len() int {
+	return len(q.tasks) - q.head
+}
+
+func (q *queue) push(t Executable) {
+	if q.head > 0 && q.head >= len(q.tasks)/2 {
+		// Reclaim the space of the tasks already popped.
+		n := copy(q.tasks, q.tasks[q.head:])
+		clear(q.tasks[n:])
+		q.tasks = q.tasks[:n]
+		q.head = 0
+	}
+	q.tasks = append(q.tasks, t)
+}
+
+func (q *queue) peek() Executable {
+	return q.tasks[q.head]
+}
+
+func (q *queue) pop() Executable {
+	t := q.tasks[q.head]
+	q.tasks[q.head] = nil
+	q.head++
+	return t
+}
+
+// inlinesDependencies reports whether e runs its own dependencies.
+func inlinesDependencies(e Executable) bool {
+	i, ok := e.(interface{ runsDependenciesInline() bool })
+	return ok && i.runsDependenciesInline()
+}
+
+// tagsOf returns the groups e belongs to, if any.
+func tagsOf(e Executable) []string {
+	if t, ok := e.(Tagged); ok {
+		return t.GetTags()
+	}
+	return nil
+}
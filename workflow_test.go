@@ -0,0 +1,98 @@
+package taskflow_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/josuedeavila/taskflow"
+)
+
+func newDoublingWorkflow(t *testing.T) *taskflow.Workflow {
+	t.Helper()
+
+	double := taskflow.NewTask("double", func(ctx context.Context, input int) (int, error) {
+		return input * 2, nil
+	})
+	format := taskflow.NewTask("format", func(ctx context.Context, input int) (string, error) {
+		return fmt.Sprintf("result=%d", input), nil
+	}).After(double)
+
+	wf, err := taskflow.NewWorkflow(format)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return wf
+}
+
+func TestWorkflowRunRepeatedly(t *testing.T) {
+	wf := newDoublingWorkflow(t)
+
+	for i := 1; i <= 3; i++ {
+		report, err := wf.Run(context.Background(), i)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		rec, _ := report.Task("format")
+		expected := fmt.Sprintf("result=%d", i*2)
+		if rec.Result != expected {
+			t.Errorf("Expected result %q, got %v", expected, rec.Result)
+		}
+	}
+}
+
+func TestWorkflowRunConcurrently(t *testing.T) {
+	wf := newDoublingWorkflow(t)
+
+	const runs = 20
+	reports := make([]*taskflow.RunReport, runs)
+	var wg sync.WaitGroup
+
+	for i := 0; i < runs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report, err := wf.Run(context.Background(), i)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			reports[i] = report
+		}()
+	}
+	wg.Wait()
+
+	ids := make(map[string]bool)
+	for i, report := range reports {
+		if report == nil {
+			continue
+		}
+		if ids[report.RunID] {
+			t.Errorf("Expected unique run IDs, got %s twice", report.RunID)
+		}
+		ids[report.RunID] = true
+
+		rec, _ := report.Task("format")
+		expected := fmt.Sprintf("result=%d", i*2)
+		if rec.Result != expected {
+			t.Errorf("Expected run %d to get %q, got %v", i, expected, rec.Result)
+		}
+	}
+}
+
+func TestRunnerWorkflowSnapshot(t *testing.T) {
+	runner := taskflow.NewRunner().WithMaxConcurrency(1)
+	runner.Add(taskflow.NewTask("a", func(ctx context.Context, input any) (string, error) { return "a", nil }))
+
+	wf, err := runner.Workflow()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	runner.Add(taskflow.NewTask("b", func(ctx context.Context, input any) (string, error) { return "b", nil }))
+
+	if n := len(wf.Graph().Nodes()); n != 1 {
+		t.Errorf("Expected workflow to keep 1 task, got %d", n)
+	}
+}